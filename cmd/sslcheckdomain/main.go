@@ -2,18 +2,36 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/spf13/cobra"
 	"sslcheckdomain/internal/checker"
 	"sslcheckdomain/internal/config"
+	"sslcheckdomain/internal/discovery"
+	"sslcheckdomain/internal/inventory"
+	"sslcheckdomain/internal/metrics"
 	"sslcheckdomain/internal/output"
 	"sslcheckdomain/internal/provider"
-	"sslcheckdomain/internal/provider/cloudflare"
+	_ "sslcheckdomain/internal/provider/axfr"
+	_ "sslcheckdomain/internal/provider/azure"
+	_ "sslcheckdomain/internal/provider/cloudflare"
+	"sslcheckdomain/internal/provider/ct"
+	_ "sslcheckdomain/internal/provider/digitalocean"
+	_ "sslcheckdomain/internal/provider/exec"
+	_ "sslcheckdomain/internal/provider/gandi"
+	_ "sslcheckdomain/internal/provider/gcloud"
+	_ "sslcheckdomain/internal/provider/hetzner"
+	_ "sslcheckdomain/internal/provider/kubernetes"
+	_ "sslcheckdomain/internal/provider/terraform"
+	_ "sslcheckdomain/internal/provider/webserver"
+	_ "sslcheckdomain/internal/provider/zonefile"
 	"sslcheckdomain/pkg/models"
 )
 
@@ -23,16 +41,24 @@ var (
 	BuildTime = "unknown"
 
 	// CLI flags
-	providerFlag    string
-	zoneFlag        string
-	expiringInFlag  int
-	thresholdFlag   int
-	outputFlag      string
-	concurrentFlag  int
-	verboseFlag     bool
-	timeoutFlag     int
-	versionFlag     bool
-	testDomainFlag  string
+	providerFlag     string
+	zoneFlag         string
+	expiringInFlag   int
+	thresholdFlag    int
+	criticalFlag     int
+	outputFlag       string
+	columnsFlag      []string
+	templateFlag     string
+	templateFileFlag string
+	pushgatewayFlag  string
+	pushJobFlag      string
+	pushLabelsFlag   map[string]string
+	concurrentFlag   int
+	verboseFlag      bool
+	timeoutFlag      int
+	versionFlag      bool
+	testDomainFlag   string
+	fromFileFlag     string
 )
 
 func main() {
@@ -60,28 +86,53 @@ SSL certificate expiration status, displaying results sorted by expiration date.
   # Check specific domains
   sslcheckdomain example.com api.example.com
 
+  # Check the domains listed in a file (or "-" for stdin)
+  sslcheckdomain --from-file domains.txt
+
   # Show only certificates expiring in 7 days
   sslcheckdomain --expiring-in 7
 
   # Output as JSON
   sslcheckdomain --output json
 
+  # Render a custom Go template
+  sslcheckdomain --output template --template-file report.tmpl
+  sslcheckdomain --output template --template slack
+
+  # Export selected columns for a spreadsheet
+  sslcheckdomain --output csv --columns domain,expires_at,issuer,sans > certs.csv
+
+  # Push metrics to a Prometheus Pushgateway after a batch run
+  sslcheckdomain --output json --pushgateway http://pushgateway:9091 --push-label env=prod
+
   # Check specific zone
-  sslcheckdomain --zone example.com`,
+  sslcheckdomain --zone example.com
+
+  # List the available DNS providers and their settings
+  sslcheckdomain providers`,
+	Args: cobra.ArbitraryArgs,
 	RunE: run,
 }
 
 func init() {
-	rootCmd.Flags().StringVarP(&providerFlag, "provider", "p", "", "DNS provider (cloudflare, route53)")
+	rootCmd.Flags().StringVarP(&providerFlag, "provider", "p", "", fmt.Sprintf("DNS provider (%s)", strings.Join(provider.AvailableProviders(), ", ")))
 	rootCmd.Flags().StringVarP(&zoneFlag, "zone", "z", "", "Filter by specific zone/domain")
 	rootCmd.Flags().IntVarP(&expiringInFlag, "expiring-in", "e", 0, "Show only certs expiring in N days (0 = show all)")
 	rootCmd.Flags().IntVarP(&thresholdFlag, "threshold", "t", 0, "Warning threshold in days (default from config)")
-	rootCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Output format (table, json, prometheus)")
+	rootCmd.Flags().IntVar(&criticalFlag, "critical", 0, "Critical threshold in days (default from config)")
+	rootCmd.Flags().StringVarP(&outputFlag, "output", "o", "", fmt.Sprintf("Output format (%s)", strings.Join(output.Formats(), ", ")))
+	rootCmd.Flags().StringSliceVar(&columnsFlag, "columns", nil, "Columns of csv and tsv output (default all, see README)")
+	rootCmd.Flags().StringVar(&templateFlag, "template", "", fmt.Sprintf("Built-in template of template output (%s)", strings.Join(output.TemplateNames(), ", ")))
+	rootCmd.Flags().StringVar(&templateFileFlag, "template-file", "", "Go text/template file of template output")
+	rootCmd.Flags().StringVar(&pushgatewayFlag, "pushgateway", "", "Push the Prometheus metrics to this Pushgateway URL")
+	rootCmd.Flags().StringVar(&pushJobFlag, "push-job", "", fmt.Sprintf("Job label of pushed metrics (default %q)", metrics.DefaultPushJob))
+	rootCmd.Flags().StringToStringVar(&pushLabelsFlag, "push-label", nil, "Grouping labels of pushed metrics (name=value,...)")
 	rootCmd.Flags().IntVarP(&concurrentFlag, "concurrent", "c", 0, "Number of concurrent checks (default from config)")
 	rootCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Verbose output")
 	rootCmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "HTTP timeout in seconds (default from config)")
 	rootCmd.Flags().BoolVar(&versionFlag, "version", false, "Show version information")
 	rootCmd.Flags().StringVarP(&testDomainFlag, "test", "d", "", "Test a single domain (bypasses provider lookup)")
+	rootCmd.Flags().StringVar(&fromFileFlag, "from-file", "", "Read domains from a file or - for stdin (lines, csv, json, yaml)")
 }
 
 func run(cmd *cobra.Command, args []string) error {
@@ -99,7 +150,7 @@ func run(cmd *cobra.Command, args []string) error {
 
 	// Override config with CLI flags if provided
 	if providerFlag != "" {
-		cfg.Provider = providerFlag
+		cfg.SelectProvider(providerFlag)
 	}
 	if zoneFlag != "" {
 		cfg.Zone = zoneFlag
@@ -110,50 +161,79 @@ func run(cmd *cobra.Command, args []string) error {
 	if thresholdFlag > 0 {
 		cfg.Threshold = thresholdFlag
 	}
+	if criticalFlag > 0 {
+		cfg.Critical = criticalFlag
+	}
 	if outputFlag != "" {
 		cfg.Output = outputFlag
 	}
+	if len(columnsFlag) > 0 {
+		cfg.Columns = config.SplitList(columnsFlag)
+	}
+	if templateFlag != "" {
+		cfg.Template = templateFlag
+	}
+	if templateFileFlag != "" {
+		cfg.TemplateFile = templateFileFlag
+	}
 	if concurrentFlag > 0 {
 		cfg.Concurrent = concurrentFlag
 	}
 	if timeoutFlag > 0 {
 		cfg.Timeout = timeoutFlag
 	}
+	if pushgatewayFlag != "" {
+		cfg.Pushgateway = pushgatewayFlag
+	}
+	if pushJobFlag != "" {
+		cfg.PushJob = pushJobFlag
+	}
+	for name, value := range pushLabelsFlag {
+		if cfg.PushLabels == nil {
+			cfg.PushLabels = make(map[string]string)
+		}
+		cfg.PushLabels[name] = value
+	}
 	cfg.Verbose = verboseFlag
 	cfg.Domains = args
 
-	// Validate configuration (skip provider validation if using --test flag)
-	if testDomainFlag == "" {
+	// Validate configuration (skip provider validation if using --test or --from-file)
+	if testDomainFlag == "" && fromFileFlag == "" {
 		if err := cfg.Validate(); err != nil {
 			return fmt.Errorf("invalid configuration: %w", err)
 		}
 	} else {
-		// Only validate non-provider settings when using --test
+		// Only validate non-provider settings when using --test or --from-file
 		if cfg.Timeout <= 0 {
 			return fmt.Errorf("timeout must be greater than 0")
 		}
 		if cfg.Concurrent <= 0 {
 			return fmt.Errorf("concurrent must be greater than 0")
 		}
-		if cfg.Threshold < 0 {
-			return fmt.Errorf("threshold must be non-negative")
+		if err := cfg.ValidateThresholds(); err != nil {
+			return err
 		}
-		validOutputs := map[string]bool{
-			"table":      true,
-			"json":       true,
-			"prometheus": true,
+		if err := output.ValidateFormat(cfg.Output); err != nil {
+			return err
 		}
-		if !validOutputs[cfg.Output] {
-			return fmt.Errorf("invalid output format: %s (valid: table, json, prometheus)", cfg.Output)
+		if err := output.ValidateColumns(cfg.Columns); err != nil {
+			return err
+		}
+	}
+	if cfg.Pushgateway != "" {
+		if err := newPusher(cfg).Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
 		}
 	}
 
 	if cfg.Verbose {
 		fmt.Fprintf(os.Stderr, "Configuration loaded:\n")
-		fmt.Fprintf(os.Stderr, "  Provider: %s\n", cfg.Provider)
+		for _, inst := range cfg.Instances() {
+			fmt.Fprintf(os.Stderr, "  Provider: %s (%s)\n", inst.Name, inst.Type)
+		}
 		fmt.Fprintf(os.Stderr, "  Timeout: %ds\n", cfg.Timeout)
 		fmt.Fprintf(os.Stderr, "  Concurrent: %d\n", cfg.Concurrent)
-		fmt.Fprintf(os.Stderr, "  Threshold: %d days\n", cfg.Threshold)
+		fmt.Fprintf(os.Stderr, "  Threshold: %d days (critical %d days)\n", cfg.Threshold, cfg.CriticalThreshold())
 		fmt.Fprintf(os.Stderr, "  Output: %s\n", cfg.Output)
 		fmt.Fprintf(os.Stderr, "\n")
 	}
@@ -162,53 +242,82 @@ func run(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
 	// Get domains to check
-	var domains []string
+	var targets []models.Target
 	if !cfg.Verbose && testDomainFlag == "" {
 		// Show spinner only if not in verbose mode and not testing a single domain
 		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
 		s.Suffix = " Fetching domains from provider..."
 		s.Start()
-		domains, err = getDomains(ctx, cfg)
+		targets, err = getTargets(ctx, cfg)
 		s.Stop()
 	} else {
-		domains, err = getDomains(ctx, cfg)
+		targets, err = getTargets(ctx, cfg)
 	}
 
 	if err != nil {
-		return fmt.Errorf("failed to get domains: %w", err)
+		if len(targets) == 0 {
+			return fmt.Errorf("failed to get domains: %w", err)
+		}
+		// Some provider instances failed; check what was discovered
+		fmt.Fprintf(os.Stderr, "Warning: failed to get some domains: %v\n", err)
 	}
 
-	if len(domains) == 0 {
+	if len(targets) == 0 {
 		return fmt.Errorf("no domains to check")
 	}
 
 	if cfg.Verbose {
-		fmt.Fprintf(os.Stderr, "Found %d domains to check\n", len(domains))
+		fmt.Fprintf(os.Stderr, "Found %d domains to check\n", len(targets))
 	}
 
+	// Resolve per-domain, per-zone and per-tag thresholds
+	cfg.ApplyThresholds(targets)
+
 	// Check SSL certificates
 	sslChecker := checker.New(time.Duration(cfg.Timeout)*time.Second, cfg.Concurrent)
 
+	formatter, err := output.GetFormatter(cfg.Output, formatterOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create formatter: %w", err)
+	}
+
 	if cfg.Verbose {
 		fmt.Fprintf(os.Stderr, "Checking SSL certificates...\n")
 	}
 
+	// Streaming formatters write each certificate as soon as it is checked,
+	// unless the certificates are needed for pushing metrics
+	if stream, ok := formatter.(output.StreamFormatter); ok && cfg.Pushgateway == "" {
+		report, err := streamReport(ctx, sslChecker, stream, targets, cfg)
+		if err != nil {
+			return fmt.Errorf("failed to format output: %w", err)
+		}
+		os.Exit(getExitCode(formatter, report))
+	}
+
 	var certificates []models.Certificate
 	if !cfg.Verbose {
 		// Show spinner only if not in verbose mode
 		s := spinner.New(spinner.CharSets[14], 100*time.Millisecond)
-		s.Suffix = fmt.Sprintf(" Checking SSL certificates for %d domains...", len(domains))
+		s.Suffix = fmt.Sprintf(" Checking SSL certificates for %d domains...", len(targets))
 		s.Start()
-		certificates, err = sslChecker.CheckDomains(ctx, domains, cfg.Threshold)
+		certificates, err = sslChecker.CheckTargets(ctx, targets, cfg.Threshold, cfg.CriticalThreshold())
 		s.Stop()
 	} else {
-		certificates, err = sslChecker.CheckDomains(ctx, domains, cfg.Threshold)
+		certificates, err = sslChecker.CheckTargets(ctx, targets, cfg.Threshold, cfg.CriticalThreshold())
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to check certificates: %w", err)
 	}
 
+	// Every push replaces the whole group, so the pushed metrics cover the
+	// certificates removed by the expiring-in filter as well
+	var pushed []metrics.Family
+	if cfg.Pushgateway != "" {
+		pushed = metrics.Families(createReport(certificates))
+	}
+
 	// Filter by expiring-in if specified
 	if cfg.ExpiringIn > 0 {
 		filtered := make([]models.Certificate, 0)
@@ -233,62 +342,97 @@ func run(cmd *cobra.Command, args []string) error {
 	}
 
 	// Format and display output
-	formatter, err := output.GetFormatter(cfg.Output)
-	if err != nil {
-		return fmt.Errorf("failed to create formatter: %w", err)
-	}
-
 	if err := formatter.Format(report); err != nil {
 		return fmt.Errorf("failed to format output: %w", err)
 	}
 
+	if cfg.Pushgateway != "" {
+		pusher := newPusher(cfg)
+		if err := pusher.Push(ctx, pushed); err != nil {
+			return fmt.Errorf("failed to push metrics to %s: %w", pusher.Redacted(), err)
+		}
+		if cfg.Verbose {
+			fmt.Fprintf(os.Stderr, "Pushed metrics to %s\n", pusher.Redacted())
+		}
+	}
+
 	// Set exit code based on results
-	exitCode := getExitCode(report)
+	exitCode := getExitCode(formatter, report)
 	os.Exit(exitCode)
 
 	return nil
 }
 
-func getDomains(ctx context.Context, cfg *config.Config) ([]string, error) {
+func getTargets(ctx context.Context, cfg *config.Config) ([]models.Target, error) {
 	// If test domain flag is provided, use it (highest priority)
 	if testDomainFlag != "" {
-		return []string{testDomainFlag}, nil
+		return []models.Target{{Domain: testDomainFlag}}, nil
 	}
 
-	// If specific domains provided via CLI, use those
-	if len(cfg.Domains) > 0 {
-		return cfg.Domains, nil
+	targets, err := discoverTargets(ctx, cfg)
+	if len(targets) == 0 {
+		return nil, err
 	}
 
-	// Otherwise, fetch from DNS provider
-	var dnsProvider provider.DNSProvider
-	var err error
+	expanded, expandErr := expandWildcards(ctx, cfg, targets)
+	return expanded, errors.Join(err, expandErr)
+}
+
+// expandWildcards replaces wildcard records by concrete hostnames
+func expandWildcards(ctx context.Context, cfg *config.Config, targets []models.Target) ([]models.Target, error) {
+	expander := newWildcardExpander(cfg)
+	expanded, err := expander.Expand(ctx, targets)
+	if len(expander.Skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: skipped %d wildcard records without concrete hostnames (see wildcards in the README): %s\n",
+			len(expander.Skipped), strings.Join(expander.Skipped, ", "))
+	}
+	return expanded, err
+}
+
+func discoverTargets(ctx context.Context, cfg *config.Config) ([]models.Target, error) {
+	// If specific domains provided via CLI or a domain list, use those
+	if len(cfg.Domains) > 0 || fromFileFlag != "" {
+		targets := models.TargetsFromDomains(cfg.Domains)
+		if fromFileFlag != "" {
+			fileTargets, err := inventory.Load(fromFileFlag)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, fileTargets...)
+		}
+		return targets, nil
+	}
 
-	switch cfg.Provider {
-	case "cloudflare":
-		dnsProvider, err = cloudflare.New(cfg.CloudflareToken)
+	// Otherwise, fetch from the configured DNS provider instances
+	instances := cfg.Instances()
+	sources := make([]discovery.Source, 0, len(instances))
+	for _, inst := range instances {
+		dnsProvider, err := provider.Create(inst.Type, inst.Settings)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create cloudflare provider: %w", err)
+			return nil, fmt.Errorf("provider %s: %w", inst.Name, err)
 		}
-	case "route53":
-		return nil, fmt.Errorf("route53 provider not yet implemented")
-	default:
-		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
+		sources = append(sources, discovery.Source{
+			Instance: inst.Name,
+			Provider: dnsProvider,
+			Zones:    inst.Zones,
+		})
 	}
 
-	// Get domains
-	var domains []string
-	if cfg.Zone != "" {
-		domains, err = dnsProvider.GetDomainsByZone(ctx, cfg.Zone)
-	} else {
-		domains, err = dnsProvider.GetDomains(ctx)
+	return discovery.Discover(ctx, sources)
+}
+
+func newWildcardExpander(cfg *config.Config) *discovery.WildcardExpander {
+	hosts := make(map[string][]string)
+	for _, expansion := range cfg.Wildcards.Expand {
+		hosts[expansion.Record] = append(hosts[expansion.Record], expansion.Hosts...)
 	}
 
-	if err != nil {
-		return nil, err
+	var ctClient *ct.Client
+	if cfg.Wildcards.CT {
+		ctClient = ct.NewClient(cfg.Wildcards.CTURL, 0)
 	}
 
-	return domains, nil
+	return discovery.NewWildcardExpander(hosts, cfg.Wildcards.SampleLabel, ctClient)
 }
 
 func createReport(certificates []models.Certificate) *models.CertificateReport {
@@ -296,33 +440,69 @@ func createReport(certificates []models.Certificate) *models.CertificateReport {
 		Timestamp:    time.Now(),
 		TotalDomains: len(certificates),
 		Certificates: certificates,
-		Summary: models.ReportSummary{
-			Expired: 0,
-			Warning: 0,
-			OK:      0,
-			Error:   0,
-		},
 	}
 
 	for _, cert := range certificates {
-		switch cert.Status {
-		case models.StatusExpired:
-			report.Summary.Expired++
-		case models.StatusWarning:
-			report.Summary.Warning++
-		case models.StatusOK:
-			report.Summary.OK++
-		case models.StatusError:
-			report.Summary.Error++
-		}
+		report.Summary.Add(cert.Status)
 	}
 
 	return report
 }
 
-func getExitCode(report *models.CertificateReport) int {
-	if report.Summary.Expired > 0 {
-		return 2 // Critical: one or more certificates expired
+// streamReport checks the targets and writes every certificate as soon as
+// it is checked. The returned report holds the summary only.
+func streamReport(ctx context.Context, sslChecker *checker.SSLChecker, stream output.StreamFormatter, targets []models.Target, cfg *config.Config) (*models.CertificateReport, error) {
+	report := createReport(nil)
+
+	if err := stream.Begin(); err != nil {
+		return nil, err
+	}
+	for cert := range sslChecker.StreamTargets(ctx, targets, cfg.Threshold, cfg.CriticalThreshold()) {
+		if cfg.ExpiringIn > 0 && cert.DaysLeft > cfg.ExpiringIn {
+			continue
+		}
+		if err := stream.WriteCertificate(cert); err != nil {
+			return nil, err
+		}
+		report.TotalDomains++
+		report.Summary.Add(cert.Status)
+	}
+	if err := stream.End(); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// newPusher returns the Pushgateway client of the configuration
+func newPusher(cfg *config.Config) *metrics.Pusher {
+	return &metrics.Pusher{
+		URL:      cfg.Pushgateway,
+		Job:      cfg.PushJob,
+		Grouping: cfg.PushLabels,
+		Client:   &http.Client{Timeout: time.Duration(cfg.Timeout) * time.Second},
+	}
+}
+
+// formatterOptions returns the formatter settings from the configuration
+func formatterOptions(cfg *config.Config) output.Options {
+	return output.Options{
+		Warning:      cfg.Threshold,
+		Critical:     cfg.CriticalThreshold(),
+		Columns:      cfg.Columns,
+		Template:     cfg.Template,
+		TemplateFile: cfg.TemplateFile,
+	}
+}
+
+func getExitCode(formatter output.Formatter, report *models.CertificateReport) int {
+	// Plugin formats define their own exit code semantics
+	if ec, ok := formatter.(output.ExitCoder); ok {
+		return ec.ExitCode(report)
+	}
+
+	if report.Summary.Expired > 0 || report.Summary.Critical > 0 {
+		return 2 // Critical: certificates expired or within the critical threshold
 	}
 	if report.Summary.Warning > 0 {
 		return 1 // Warning: certificates expiring soon
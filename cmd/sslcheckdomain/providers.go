@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"sslcheckdomain/internal/provider"
+)
+
+var providersCmd = &cobra.Command{
+	Use:   "providers",
+	Short: "List the available DNS providers and their settings",
+	Long: `List every registered DNS provider together with the settings it
+understands. Settings are read from the provider's section in the config
+file and fall back to the listed environment variable.`,
+	Args: cobra.NoArgs,
+	RunE: runProviders,
+}
+
+func init() {
+	rootCmd.AddCommand(providersCmd)
+}
+
+func runProviders(cmd *cobra.Command, args []string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	for i, def := range provider.Definitions() {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\t%s\n", def.Name, def.Description)
+
+		for _, s := range def.Settings {
+			required := "optional"
+			if s.Required {
+				required = "required"
+			}
+			env := s.Env
+			if env == "" {
+				env = "-"
+			}
+			fmt.Fprintf(w, "  %s.%s\t%s\t%s\t%s\n", def.Name, s.Key, env, required, s.Description)
+		}
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"sslcheckdomain/internal/config"
+	"sslcheckdomain/internal/output"
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/kubernetes"
+	"sslcheckdomain/pkg/models"
+)
+
+var (
+	// secrets command flags
+	secretsKubeconfigFlag string
+	secretsContextFlag    string
+	secretsNamespaceFlag  string
+	secretsManifestsFlag  []string
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Report the expiry of Kubernetes TLS secrets",
+	Long: `Read kubernetes.io/tls secrets directly and report the expiry of the
+certificates they hold. Secrets that are about to expire and are not
+referenced by any Ingress are flagged.
+
+Secrets are read from the cluster of the kubernetes config section, or from
+a directory of dumped secret manifests with --manifests.`,
+	Example: `  # Inspect the secrets of the current kubeconfig context
+  sslcheckdomain secrets
+
+  # Inspect dumped manifests (kubectl get secrets,ingress -A -o yaml)
+  sslcheckdomain secrets --manifests cluster-dump/`,
+	Args: cobra.NoArgs,
+	RunE: runSecrets,
+}
+
+func init() {
+	secretsCmd.Flags().StringVar(&secretsKubeconfigFlag, "kubeconfig", "", "Path to the kubeconfig file")
+	secretsCmd.Flags().StringVar(&secretsContextFlag, "context", "", "Kubeconfig context to use")
+	secretsCmd.Flags().StringVarP(&secretsNamespaceFlag, "namespace", "n", "", "Only inspect secrets of this namespace")
+	secretsCmd.Flags().StringSliceVar(&secretsManifestsFlag, "manifests", nil, "Read secret manifests from files or directories")
+	secretsCmd.Flags().IntVarP(&thresholdFlag, "threshold", "t", 0, "Warning threshold in days (default from config)")
+	secretsCmd.Flags().IntVar(&criticalFlag, "critical", 0, "Critical threshold in days (default from config)")
+	secretsCmd.Flags().StringVarP(&outputFlag, "output", "o", "", fmt.Sprintf("Output format (%s)", strings.Join(output.Formats(), ", ")))
+	secretsCmd.Flags().StringVar(&templateFlag, "template", "", fmt.Sprintf("Built-in template of template output (%s)", strings.Join(output.TemplateNames(), ", ")))
+	secretsCmd.Flags().StringVar(&templateFileFlag, "template-file", "", "Go text/template file of template output")
+	secretsCmd.Flags().StringSliceVar(&columnsFlag, "columns", nil, "Columns of csv and tsv output (default all, see README)")
+	rootCmd.AddCommand(secretsCmd)
+}
+
+func runSecrets(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if thresholdFlag > 0 {
+		cfg.Threshold = thresholdFlag
+	}
+	if criticalFlag > 0 {
+		cfg.Critical = criticalFlag
+	}
+	if outputFlag != "" {
+		cfg.Output = outputFlag
+	}
+	if len(columnsFlag) > 0 {
+		cfg.Columns = config.SplitList(columnsFlag)
+	}
+	if templateFlag != "" {
+		cfg.Template = templateFlag
+	}
+	if templateFileFlag != "" {
+		cfg.TemplateFile = templateFileFlag
+	}
+
+	if err := cfg.ValidateThresholds(); err != nil {
+		return err
+	}
+	if err := output.ValidateFormat(cfg.Output); err != nil {
+		return err
+	}
+	if err := output.ValidateColumns(cfg.Columns); err != nil {
+		return err
+	}
+
+	var kubeCfg kubernetes.Config
+	if err := provider.Decode(cfg.ProviderSettings["kubernetes"], &kubeCfg); err != nil {
+		return fmt.Errorf("invalid kubernetes settings: %w", err)
+	}
+	if secretsKubeconfigFlag != "" {
+		kubeCfg.Kubeconfig = secretsKubeconfigFlag
+	}
+	if secretsContextFlag != "" {
+		kubeCfg.Context = secretsContextFlag
+	}
+	if secretsNamespaceFlag != "" {
+		kubeCfg.Namespace = secretsNamespaceFlag
+	}
+	if len(secretsManifestsFlag) > 0 {
+		kubeCfg.Manifests = secretsManifestsFlag
+	}
+
+	source, err := kubernetes.NewSource(kubeCfg)
+	if err != nil {
+		return err
+	}
+
+	// Threshold overrides match the first name of each certificate
+	thresholds := func(domain string) (int, int) {
+		targets := []models.Target{{Domain: domain}}
+		cfg.ApplyThresholds(targets)
+		return targets[0].Threshold, targets[0].Critical
+	}
+
+	certificates, err := kubernetes.InspectSecrets(context.Background(), source, thresholds)
+	if err != nil {
+		return fmt.Errorf("failed to inspect secrets: %w", err)
+	}
+	if len(certificates) == 0 {
+		return fmt.Errorf("no TLS secrets found")
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].DaysLeft < certificates[j].DaysLeft
+	})
+
+	report := createReport(certificates)
+
+	formatter, err := output.GetFormatter(cfg.Output, formatterOptions(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create formatter: %w", err)
+	}
+	if err := formatter.Format(report); err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+
+	os.Exit(getExitCode(formatter, report))
+	return nil
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"slices"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"sslcheckdomain/internal/checker"
+	"sslcheckdomain/internal/config"
+	"sslcheckdomain/internal/inventory"
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/server"
+	"sslcheckdomain/pkg/models"
+)
+
+var (
+	serveListenFlag     string
+	serveIntervalFlag   time.Duration
+	serveProbeAllowFlag []string
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run as a Prometheus exporter with background refresh",
+	Long: `Run continuously, re-discovering and re-checking certificates on a
+schedule, and serve the latest results over HTTP:
+
+  /metrics      Prometheus metrics of the latest check (OpenMetrics on request)
+  /api/report   The latest report as JSON
+  /healthz      200 once a recent check produced a report, 503 otherwise
+  /probe        Check ?target=host[:port] on demand, like the blackbox exporter
+
+Requests are answered from the previous results while a check runs.
+
+The server listens on all interfaces and has no authentication. /probe only
+checks the hosts and ports of the latest report, plus the hosts matching
+--probe-allow ("example.com", "*.example.com", or "*" for any host). Allowing
+any host lets everyone who reaches the port make the server connect anywhere,
+so restrict access to the network Prometheus scrapes from.`,
+	Example: `  # Check the configured providers every hour
+  sslcheckdomain serve
+
+  # Check a domain list every 15 minutes on another port
+  sslcheckdomain serve --from-file domains.txt --interval 15m --listen :9100
+
+  # Also allow on-demand probes of any host below example.com
+  sslcheckdomain serve --probe-allow '*.example.com'`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveListenFlag, "listen", "", fmt.Sprintf("Address to listen on (default %q)", server.DefaultListen))
+	serveCmd.Flags().DurationVar(&serveIntervalFlag, "interval", 0, "Time between checks (default from config, 1h)")
+	serveCmd.Flags().StringSliceVar(&serveProbeAllowFlag, "probe-allow", nil, "Hosts /probe may check besides the discovered ones (example.com, *.example.com, * for any)")
+	serveCmd.Flags().StringVarP(&providerFlag, "provider", "p", "", "DNS provider (default from config)")
+	serveCmd.Flags().StringVarP(&zoneFlag, "zone", "z", "", "Filter by specific zone/domain")
+	serveCmd.Flags().StringVar(&fromFileFlag, "from-file", "", "Read domains from a file instead of the providers (lines, csv, json, yaml)")
+	serveCmd.Flags().IntVarP(&thresholdFlag, "threshold", "t", 0, "Warning threshold in days (default from config)")
+	serveCmd.Flags().IntVar(&criticalFlag, "critical", 0, "Critical threshold in days (default from config)")
+	serveCmd.Flags().IntVarP(&concurrentFlag, "concurrent", "c", 0, "Number of concurrent checks (default from config)")
+	serveCmd.Flags().IntVar(&timeoutFlag, "timeout", 0, "HTTP timeout in seconds (default from config)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if providerFlag != "" {
+		cfg.SelectProvider(providerFlag)
+	}
+	if zoneFlag != "" {
+		cfg.Zone = zoneFlag
+	}
+	if thresholdFlag > 0 {
+		cfg.Threshold = thresholdFlag
+	}
+	if criticalFlag > 0 {
+		cfg.Critical = criticalFlag
+	}
+	if concurrentFlag > 0 {
+		cfg.Concurrent = concurrentFlag
+	}
+	if timeoutFlag > 0 {
+		cfg.Timeout = timeoutFlag
+	}
+	if serveListenFlag != "" {
+		cfg.Listen = serveListenFlag
+	}
+	if serveIntervalFlag > 0 {
+		cfg.Interval = serveIntervalFlag
+	}
+	if len(serveProbeAllowFlag) > 0 {
+		cfg.ProbeAllow = config.SplitList(serveProbeAllowFlag)
+	}
+
+	// The output format does not apply to the exporter
+	cfg.Output = "prometheus"
+	if fromFileFlag == "" {
+		if err := cfg.Validate(); err != nil {
+			return fmt.Errorf("invalid configuration: %w", err)
+		}
+	} else if err := cfg.ValidateThresholds(); err != nil {
+		return err
+	}
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("interval must be greater than 0")
+	}
+
+	// A domain list is read once rather than on every refresh, as stdin
+	// cannot be read again
+	var listTargets []models.Target
+	if fromFileFlag != "" {
+		loaded, err := inventory.Load(fromFileFlag)
+		if err != nil {
+			return err
+		}
+		listTargets = append(models.TargetsFromDomains(cfg.Domains), loaded...)
+		if len(listTargets) == 0 {
+			return fmt.Errorf("no domains to check in %s", fromFileFlag)
+		}
+	}
+
+	sslChecker := checker.New(time.Duration(cfg.Timeout)*time.Second, cfg.Concurrent)
+
+	var srv *server.Server
+	srv = server.New(server.Options{
+		Listen:   cfg.Listen,
+		Interval: cfg.Interval,
+		Refresh: func(ctx context.Context) (*models.CertificateReport, error) {
+			return refreshReport(ctx, cfg, sslChecker, listTargets)
+		},
+		Probe: func(ctx context.Context, target, sni string) (models.Certificate, error) {
+			t, err := inventory.ParseTarget(target)
+			if err != nil {
+				return models.Certificate{}, fmt.Errorf("invalid target: %w", err)
+			}
+			if !probeAllowed(t, cfg.ProbeAllow, srv.Report()) {
+				return models.Certificate{}, fmt.Errorf("%w: %s", server.ErrProbeDenied, target)
+			}
+			if sni != "" {
+				t.SNI = sni
+			}
+			targets := []models.Target{t}
+			cfg.ApplyThresholds(targets)
+			return sslChecker.CheckTarget(ctx, targets[0], cfg.Threshold, cfg.CriticalThreshold()), nil
+		},
+		Logger: log.New(os.Stderr, "", log.LstdFlags),
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return srv.Run(ctx)
+}
+
+// refreshReport discovers and checks all certificates, or those of
+// listTargets when a domain list was given. When only some sources fail, the
+// report of the discovered domains is returned with the error.
+func refreshReport(ctx context.Context, cfg *config.Config, sslChecker *checker.SSLChecker, listTargets []models.Target) (*models.CertificateReport, error) {
+	var (
+		targets []models.Target
+		err     error
+	)
+	if listTargets != nil {
+		targets, err = expandWildcards(ctx, cfg, slices.Clone(listTargets))
+	} else {
+		targets, err = getTargets(ctx, cfg)
+	}
+	if len(targets) == 0 {
+		if err == nil {
+			err = fmt.Errorf("no domains to check")
+		}
+		return nil, fmt.Errorf("failed to get domains: %w", err)
+	}
+
+	cfg.ApplyThresholds(targets)
+
+	certificates, checkErr := sslChecker.CheckTargets(ctx, targets, cfg.Threshold, cfg.CriticalThreshold())
+	if checkErr != nil {
+		return nil, fmt.Errorf("failed to check certificates: %w", checkErr)
+	}
+
+	sort.Slice(certificates, func(i, j int) bool {
+		return certificates[i].DaysLeft < certificates[j].DaysLeft
+	})
+
+	return createReport(certificates), err
+}
+
+// probeAllowed reports whether /probe may check a target: a host and port of
+// the report, or a host matching one of the patterns. "*" matches any host,
+// "*.example.com" any host below example.com.
+func probeAllowed(target models.Target, patterns []string, report *models.CertificateReport) bool {
+	host := provider.NormalizeName(target.Domain)
+	for _, pattern := range patterns {
+		pattern = provider.NormalizeName(pattern)
+		switch {
+		case pattern == "*":
+			return true
+		case provider.IsWildcard(pattern):
+			if strings.HasSuffix(host, pattern[1:]) {
+				return true
+			}
+		case pattern == host:
+			return true
+		}
+	}
+
+	if report == nil {
+		return false
+	}
+	for _, cert := range report.Certificates {
+		if provider.NormalizeName(cert.Domain) == host && portOrDefault(cert.Port) == portOrDefault(target.Port) {
+			return true
+		}
+	}
+	return false
+}
+
+// portOrDefault returns port, or 443 when it is not set
+func portOrDefault(port int) int {
+	if port == 0 {
+		return 443
+	}
+	return port
+}
@@ -3,6 +3,9 @@
 # Example script to export SSL metrics for Prometheus Node Exporter
 # Run this script via cron: */5 * * * * /path/to/prometheus-exporter.sh
 #
+# Alternatively, `sslcheckdomain serve` runs as a long-lived exporter with a
+# /metrics endpoint and background refresh, without cron or node_exporter.
+#
 
 set -euo pipefail
 
@@ -3,8 +3,13 @@ package checker
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"net"
+	"os"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
@@ -26,14 +31,34 @@ func New(timeout time.Duration, concurrent int) *SSLChecker {
 }
 
 // CheckDomains checks SSL certificates for multiple domains concurrently
-func (c *SSLChecker) CheckDomains(ctx context.Context, domains []string, threshold int) ([]models.Certificate, error) {
-	if len(domains) == 0 {
+func (c *SSLChecker) CheckDomains(ctx context.Context, domains []string, warning, critical int) ([]models.Certificate, error) {
+	return c.CheckTargets(ctx, models.TargetsFromDomains(domains), warning, critical)
+}
+
+// CheckTargets checks SSL certificates for multiple targets concurrently.
+// The warning and critical thresholds apply to targets without overrides.
+func (c *SSLChecker) CheckTargets(ctx context.Context, targets []models.Target, warning, critical int) ([]models.Certificate, error) {
+	if len(targets) == 0 {
 		return nil, fmt.Errorf("no domains to check")
 	}
 
+	// Collect results
+	certificates := make([]models.Certificate, 0, len(targets))
+	for cert := range c.StreamTargets(ctx, targets, warning, critical) {
+		certificates = append(certificates, cert)
+	}
+
+	return certificates, nil
+}
+
+// StreamTargets checks SSL certificates for multiple targets concurrently
+// and sends every result as soon as it is available, so large reports can be
+// written without holding all results in memory. The channel is closed when
+// all targets are checked.
+func (c *SSLChecker) StreamTargets(ctx context.Context, targets []models.Target, warning, critical int) <-chan models.Certificate {
 	// Create channels for work distribution
-	jobs := make(chan string, len(domains))
-	results := make(chan models.Certificate, len(domains))
+	jobs := make(chan models.Target, len(targets))
+	results := make(chan models.Certificate, c.concurrent)
 
 	// Create worker pool
 	var wg sync.WaitGroup
@@ -41,38 +66,65 @@ func (c *SSLChecker) CheckDomains(ctx context.Context, domains []string, thresho
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for domain := range jobs {
-				cert := c.checkDomain(ctx, domain, threshold)
-				results <- cert
+			for target := range jobs {
+				results <- c.checkTarget(ctx, target, warning, critical)
 			}
 		}()
 	}
 
 	// Send jobs
-	for _, domain := range domains {
-		jobs <- domain
+	for _, target := range targets {
+		jobs <- target
 	}
 	close(jobs)
 
-	// Wait for all workers to complete
+	// Close results once all workers are done
 	go func() {
 		wg.Wait()
 		close(results)
 	}()
 
-	// Collect results
-	certificates := make([]models.Certificate, 0, len(domains))
-	for cert := range results {
-		certificates = append(certificates, cert)
-	}
-
-	return certificates, nil
+	return results
 }
 
-// checkDomain checks SSL certificate for a single domain
-func (c *SSLChecker) checkDomain(ctx context.Context, domain string, threshold int) models.Certificate {
-	cert := models.Certificate{
-		Domain: domain,
+// checkTarget checks SSL certificate for a single target and records how
+// long the check took
+func (c *SSLChecker) checkTarget(ctx context.Context, target models.Target, warning, critical int) (cert models.Certificate) {
+	start := time.Now()
+	defer func() {
+		cert.Duration = time.Since(start)
+	}()
+
+	// Notes are copied as checks append to them, and the target may be
+	// checked concurrently, e.g. by a refresh and a probe
+	domain := target.Domain
+	cert = models.Certificate{
+		Domain:    domain,
+		Port:      target.Port,
+		SNI:       target.SNI,
+		Tags:      target.Tags,
+		TLSSecret: target.TLSSecret,
+		CertFile:  target.CertFile,
+		Wildcard:  target.Wildcard,
+		Notes:     slices.Clone(target.Notes),
+		Provider:  target.Provider,
+		Instance:  target.Instance,
+	}
+
+	// Per-target overrides from inventories and config
+	if target.Threshold > 0 {
+		warning = target.Threshold
+	}
+	if target.Critical > 0 {
+		critical = target.Critical
+	}
+	port := target.Port
+	if port == 0 {
+		port = 443
+	}
+	serverName := target.SNI
+	if serverName == "" {
+		serverName = domain
 	}
 
 	// Create context with timeout
@@ -84,14 +136,14 @@ func (c *SSLChecker) checkDomain(ctx context.Context, domain string, threshold i
 		Timeout: c.timeout,
 	}
 
-	conn, err := tls.DialWithDialer(dialer, "tcp", domain+":443", &tls.Config{
-		ServerName: domain,
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(domain, strconv.Itoa(port)), &tls.Config{
+		ServerName: serverName,
 		MinVersion: tls.VersionTLS12,
 	})
 
 	if err != nil {
 		cert.Error = fmt.Errorf("failed to connect: %w", err)
-		cert.DetermineStatus(threshold)
+		cert.DetermineStatus(warning, critical)
 		return cert
 	}
 	defer conn.Close()
@@ -100,7 +152,7 @@ func (c *SSLChecker) checkDomain(ctx context.Context, domain string, threshold i
 	select {
 	case <-checkCtx.Done():
 		cert.Error = fmt.Errorf("check timeout")
-		cert.DetermineStatus(threshold)
+		cert.DetermineStatus(warning, critical)
 		return cert
 	default:
 	}
@@ -108,25 +160,112 @@ func (c *SSLChecker) checkDomain(ctx context.Context, domain string, threshold i
 	// Get certificate information
 	if len(conn.ConnectionState().PeerCertificates) == 0 {
 		cert.Error = fmt.Errorf("no certificate found")
-		cert.DetermineStatus(threshold)
+		cert.DetermineStatus(warning, critical)
 		return cert
 	}
 
-	peerCert := conn.ConnectionState().PeerCertificates[0]
-
-	cert.ExpiresAt = peerCert.NotAfter
-	cert.IssuedAt = peerCert.NotBefore
-	cert.Issuer = peerCert.Issuer.CommonName
-	cert.Subject = peerCert.Subject.CommonName
-	cert.SerialNumber = peerCert.SerialNumber.String()
+	peerCerts := conn.ConnectionState().PeerCertificates
+	peerCert := peerCerts[0]
+	DescribeChain(&cert, peerCerts)
+	if target.CertFile != "" {
+		compareCertFile(&cert, peerCert, target.CertFile)
+	}
 
 	// Determine status
-	cert.DetermineStatus(threshold)
+	cert.DetermineStatus(warning, critical)
 
 	return cert
 }
 
+// CheckTarget checks SSL certificate for a single target (public method)
+func (c *SSLChecker) CheckTarget(ctx context.Context, target models.Target, warning, critical int) models.Certificate {
+	return c.checkTarget(ctx, target, warning, critical)
+}
+
 // CheckDomain checks SSL certificate for a single domain (public method)
-func (c *SSLChecker) CheckDomain(ctx context.Context, domain string, threshold int) models.Certificate {
-	return c.checkDomain(ctx, domain, threshold)
+func (c *SSLChecker) CheckDomain(ctx context.Context, domain string, warning, critical int) models.Certificate {
+	return c.checkTarget(ctx, models.Target{Domain: domain}, warning, critical)
+}
+
+// Describe copies the details of an X.509 certificate into cert
+func Describe(cert *models.Certificate, x509Cert *x509.Certificate) {
+	cert.ExpiresAt = x509Cert.NotAfter
+	cert.IssuedAt = x509Cert.NotBefore
+	cert.Issuer = x509Cert.Issuer.CommonName
+	cert.Subject = x509Cert.Subject.CommonName
+	cert.SerialNumber = x509Cert.SerialNumber.String()
+
+	cert.SANs = append([]string{}, x509Cert.DNSNames...)
+	for _, ip := range x509Cert.IPAddresses {
+		cert.SANs = append(cert.SANs, ip.String())
+	}
+}
+
+// DescribeChain describes the leaf of a certificate chain, leaf first, and
+// records the certificates presented after it
+func DescribeChain(cert *models.Certificate, chain []*x509.Certificate) {
+	Describe(cert, chain[0])
+
+	cert.Chain = make([]models.ChainCert, 0, len(chain)-1)
+	for _, c := range chain[1:] {
+		cert.Chain = append(cert.Chain, models.ChainCert{
+			Subject:      c.Subject.CommonName,
+			Issuer:       c.Issuer.CommonName,
+			ExpiresAt:    c.NotAfter,
+			SerialNumber: c.SerialNumber.String(),
+		})
+	}
+}
+
+// compareCertFile notes when the served certificate is not the leaf of the
+// certificate file the server is configured with, e.g. because the server
+// was not reloaded after a renewal
+func compareCertFile(cert *models.Certificate, served *x509.Certificate, file string) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		cert.Notes = append(cert.Notes, fmt.Sprintf("cannot read certificate file: %v", err))
+		return
+	}
+	chain, err := ParsePEM(data)
+	if err != nil {
+		cert.Notes = append(cert.Notes, fmt.Sprintf("cannot parse certificate file %s: %v", file, err))
+		return
+	}
+
+	onDisk := chain[0]
+	if onDisk.Equal(served) {
+		return
+	}
+
+	note := fmt.Sprintf("served certificate differs from %s (serial %s, expires %s)",
+		file, onDisk.SerialNumber, onDisk.NotAfter.Format("2006-01-02"))
+	if onDisk.NotAfter.After(served.NotAfter) {
+		note += "; reload the server?"
+	}
+	cert.Notes = append(cert.Notes, note)
+}
+
+// ParsePEM parses the certificates of a PEM bundle, leaf first
+func ParsePEM(data []byte) ([]*x509.Certificate, error) {
+	certs := make([]*x509.Certificate, 0)
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		c, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certs = append(certs, c)
+	}
+
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no certificate found")
+	}
+	return certs, nil
 }
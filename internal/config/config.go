@@ -4,34 +4,75 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
+	"sslcheckdomain/internal/output"
+	"sslcheckdomain/internal/provider"
 )
 
 // Config holds application configuration
 type Config struct {
 	// Provider settings
 	Provider         string
-	CloudflareToken  string
-	CloudflareEmail  string
-	CloudflareAccountID string
-
-	// AWS settings
-	AWSAccessKeyID     string
-	AWSSecretAccessKey string
-	AWSRegion          string
+	ProviderSettings map[string]provider.Settings
+	Providers        []ProviderInstance
+	Wildcards        WildcardConfig
+	Overrides        []ThresholdOverride
 
 	// Application settings
 	Timeout    int
 	Concurrent int
 	Threshold  int
+	Critical   int // 0 = DefaultCritical, see CriticalThreshold
 	Output     string
+	Columns    []string
 	Verbose    bool
 
+	// Template output settings
+	Template     string
+	TemplateFile string
+
+	// Exporter settings of the serve command
+	Listen     string
+	Interval   time.Duration
+	ProbeAllow []string
+
+	// Pushgateway settings of batch runs
+	Pushgateway string
+	PushJob     string
+	PushLabels  map[string]string
+
 	// Filter settings
-	Zone        string
-	ExpiringIn  int
-	Domains     []string
+	Zone       string
+	ExpiringIn int
+	Domains    []string
+}
+
+// ProviderInstance is a named DNS provider configured in the providers list.
+// Keys other than name, type and zones are passed to the provider as settings.
+type ProviderInstance struct {
+	Name     string            `mapstructure:"name"`
+	Type     string            `mapstructure:"type"`
+	Zones    []string          `mapstructure:"zones"`
+	Settings provider.Settings `mapstructure:",remain"`
+}
+
+// WildcardConfig controls how wildcard records are mapped to hostnames
+type WildcardConfig struct {
+	// Expand lists concrete hostnames per wildcard record
+	Expand []WildcardExpansion `mapstructure:"expand"`
+	// SampleLabel is prepended to every wildcard's base domain
+	SampleLabel string `mapstructure:"sample_label"`
+	// CT looks up hostnames below each wildcard in CT logs
+	CT    bool   `mapstructure:"ct"`
+	CTURL string `mapstructure:"ct_url"`
+}
+
+// WildcardExpansion maps a wildcard record to concrete hostnames
+type WildcardExpansion struct {
+	Record string   `mapstructure:"record"`
+	Hosts  []string `mapstructure:"hosts"`
 }
 
 // Load loads configuration from environment variables and config file
@@ -42,21 +83,13 @@ func Load() (*Config, error) {
 	viper.SetDefault("threshold", 30)
 	viper.SetDefault("output", "table")
 	viper.SetDefault("provider", "cloudflare")
-	viper.SetDefault("aws_region", "us-east-1")
+	viper.SetDefault("interval", "1h")
 
 	// Bind environment variables
 	viper.SetEnvPrefix("SSL_CHECK")
 	viper.AutomaticEnv()
 	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
-	// Also bind Cloudflare and AWS specific env vars
-	viper.BindEnv("cloudflare_token", "CLOUDFLARE_API_TOKEN")
-	viper.BindEnv("cloudflare_email", "CLOUDFLARE_EMAIL")
-	viper.BindEnv("cloudflare_account_id", "CLOUDFLARE_ACCOUNT_ID")
-	viper.BindEnv("aws_access_key_id", "AWS_ACCESS_KEY_ID")
-	viper.BindEnv("aws_secret_access_key", "AWS_SECRET_ACCESS_KEY")
-	viper.BindEnv("aws_region", "AWS_REGION")
-
 	// Try to load config file from multiple locations
 	viper.SetConfigName("sslcheckdomain")
 	viper.SetConfigType("yaml")
@@ -81,35 +114,156 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		Provider:            viper.GetString("provider"),
-		CloudflareToken:     viper.GetString("cloudflare_token"),
-		CloudflareEmail:     viper.GetString("cloudflare_email"),
-		CloudflareAccountID: viper.GetString("cloudflare_account_id"),
-		AWSAccessKeyID:      viper.GetString("aws_access_key_id"),
-		AWSSecretAccessKey:  viper.GetString("aws_secret_access_key"),
-		AWSRegion:           viper.GetString("aws_region"),
-		Timeout:             viper.GetInt("timeout"),
-		Concurrent:          viper.GetInt("concurrent"),
-		Threshold:           viper.GetInt("threshold"),
-		Output:              viper.GetString("output"),
+		Provider:         viper.GetString("provider"),
+		ProviderSettings: loadProviderSettings(),
+		Timeout:          viper.GetInt("timeout"),
+		Concurrent:       viper.GetInt("concurrent"),
+		Threshold:        viper.GetInt("threshold"),
+		Critical:         viper.GetInt("critical"),
+		Output:           viper.GetString("output"),
+		Columns:          SplitList(viper.GetStringSlice("columns")),
+		Template:         viper.GetString("template"),
+		TemplateFile:     viper.GetString("template_file"),
+		Listen:           viper.GetString("listen"),
+		Interval:         viper.GetDuration("interval"),
+		ProbeAllow:       SplitList(viper.GetStringSlice("probe_allow")),
+		Pushgateway:      viper.GetString("pushgateway"),
+		PushJob:          viper.GetString("push_job"),
+		PushLabels:       viper.GetStringMapString("push_labels"),
+	}
+
+	if err := viper.UnmarshalKey("providers", &cfg.Providers); err != nil {
+		return nil, fmt.Errorf("error reading providers: %w", err)
+	}
+
+	if err := viper.UnmarshalKey("wildcards", &cfg.Wildcards); err != nil {
+		return nil, fmt.Errorf("error reading wildcards: %w", err)
+	}
+
+	if err := viper.UnmarshalKey("overrides", &cfg.Overrides); err != nil {
+		return nil, fmt.Errorf("error reading overrides: %w", err)
 	}
 
 	return cfg, nil
 }
 
+// Instances returns the provider instances to discover domains from.
+// Without a providers list, a single instance of Provider is used. Instance
+// settings are layered over the provider's config section, and a Zone filter
+// replaces the zones configured per instance. The zones also fill the zones
+// setting of providers that need zone names and have none configured.
+func (c *Config) Instances() []ProviderInstance {
+	configured := c.Providers
+	if len(configured) == 0 {
+		configured = []ProviderInstance{{Name: c.Provider, Type: c.Provider}}
+	}
+
+	instances := make([]ProviderInstance, 0, len(configured))
+	for _, inst := range configured {
+		if inst.Type == "" {
+			inst.Type = inst.Name
+		}
+		if inst.Name == "" {
+			inst.Name = inst.Type
+		}
+
+		settings := make(provider.Settings)
+		for key, value := range c.ProviderSettings[inst.Type] {
+			settings[key] = value
+		}
+		for key, value := range inst.Settings {
+			settings[key] = value
+		}
+		inst.Settings = settings
+
+		if c.Zone != "" {
+			inst.Zones = []string{c.Zone}
+		}
+
+		// Providers that need zone names (e.g. axfr) take them from the filter
+		if _, ok := settings["zones"]; !ok && len(inst.Zones) > 0 {
+			settings["zones"] = inst.Zones
+		}
+
+		instances = append(instances, inst)
+	}
+
+	return instances
+}
+
+// loadProviderSettings reads the config section of every registered provider.
+// Settings missing from the section fall back to the legacy flat keys
+// (e.g. cloudflare_token) and to variables defined in the .env file.
+func loadProviderSettings() map[string]provider.Settings {
+	sections := make(map[string]provider.Settings)
+
+	for _, def := range provider.Definitions() {
+		section := provider.Settings(viper.GetStringMap(def.Name))
+
+		for _, s := range def.Settings {
+			if _, ok := section[s.Key]; ok {
+				continue
+			}
+			if value := viper.Get(def.Name + "_" + s.Key); value != nil {
+				section[s.Key] = value
+				continue
+			}
+			if s.Env != "" {
+				if value := viper.Get(strings.ToLower(s.Env)); value != nil {
+					section[s.Key] = value
+				}
+			}
+		}
+
+		sections[def.Name] = section
+	}
+
+	return sections
+}
+
+// SplitList splits comma separated entries of a list, as given in
+// environment variables or flags, dropping empty entries
+func SplitList(values []string) []string {
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
+
+// SelectProvider restricts discovery to the provider instances whose name or
+// type matches. If none match, a single instance of that provider type is used.
+func (c *Config) SelectProvider(name string) {
+	c.Provider = name
+
+	selected := make([]ProviderInstance, 0, len(c.Providers))
+	for _, inst := range c.Providers {
+		if inst.Name == name || inst.Type == name {
+			selected = append(selected, inst)
+		}
+	}
+	c.Providers = selected
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
-	switch c.Provider {
-	case "cloudflare":
-		if c.CloudflareToken == "" {
-			return fmt.Errorf("CLOUDFLARE_API_TOKEN is required for cloudflare provider")
+	seen := make(map[string]bool)
+	for _, inst := range c.Instances() {
+		if seen[inst.Name] {
+			return fmt.Errorf("duplicate provider instance name: %s", inst.Name)
 		}
-	case "route53":
-		if c.AWSAccessKeyID == "" || c.AWSSecretAccessKey == "" {
-			return fmt.Errorf("AWS credentials are required for route53 provider")
+		seen[inst.Name] = true
+
+		if err := provider.Validate(inst.Type, inst.Settings); err != nil {
+			if len(c.Providers) > 0 {
+				return fmt.Errorf("provider %s: %w", inst.Name, err)
+			}
+			return err
 		}
-	default:
-		return fmt.Errorf("unsupported provider: %s (supported: cloudflare, route53)", c.Provider)
 	}
 
 	if c.Timeout <= 0 {
@@ -120,18 +274,16 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("concurrent must be greater than 0")
 	}
 
-	if c.Threshold < 0 {
-		return fmt.Errorf("threshold must be non-negative")
+	if err := c.ValidateThresholds(); err != nil {
+		return err
 	}
 
-	validOutputs := map[string]bool{
-		"table":      true,
-		"json":       true,
-		"prometheus": true,
+	if err := output.ValidateFormat(c.Output); err != nil {
+		return err
 	}
 
-	if !validOutputs[c.Output] {
-		return fmt.Errorf("invalid output format: %s (valid: table, json, prometheus)", c.Output)
+	if err := output.ValidateColumns(c.Columns); err != nil {
+		return err
 	}
 
 	return nil
@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/pkg/models"
+)
+
+// DefaultCritical is the critical threshold in days when none is set
+const DefaultCritical = 7
+
+// ThresholdOverride sets the warning and/or critical threshold of the
+// certificates matching exactly one of Domain, Zone or Tag
+type ThresholdOverride struct {
+	// Domain matches a single hostname
+	Domain string `mapstructure:"domain"`
+	// Zone matches a domain and all hostnames below it
+	Zone string `mapstructure:"zone"`
+	// Tag matches targets carrying the tag
+	Tag string `mapstructure:"tag"`
+	// Threshold is the warning threshold in days (0 = inherit)
+	Threshold int `mapstructure:"threshold"`
+	// Critical is the critical threshold in days (0 = inherit)
+	Critical int `mapstructure:"critical"`
+}
+
+// ValidateThresholds checks the warning and critical thresholds and the
+// threshold overrides
+func (c *Config) ValidateThresholds() error {
+	if c.Threshold < 0 {
+		return fmt.Errorf("threshold must be non-negative")
+	}
+	if c.Critical < 0 {
+		return fmt.Errorf("critical threshold must be non-negative")
+	}
+	if c.Critical > 0 && c.Critical > c.Threshold {
+		return fmt.Errorf("critical threshold (%d) must not exceed the warning threshold (%d)", c.Critical, c.Threshold)
+	}
+
+	for i, o := range c.Overrides {
+		set := 0
+		for _, match := range []string{o.Domain, o.Zone, o.Tag} {
+			if match != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("overrides[%d]: exactly one of domain, zone or tag is required", i)
+		}
+		if o.Threshold < 0 || o.Critical < 0 {
+			return fmt.Errorf("overrides[%d]: thresholds must be non-negative", i)
+		}
+		if o.Threshold == 0 && o.Critical == 0 {
+			return fmt.Errorf("overrides[%d]: threshold or critical is required", i)
+		}
+	}
+
+	return nil
+}
+
+// CriticalThreshold returns the global critical threshold. When none is set,
+// DefaultCritical is used, lowered to the warning threshold.
+func (c *Config) CriticalThreshold() int {
+	if c.Critical > 0 {
+		return c.Critical
+	}
+	if c.Threshold < DefaultCritical {
+		return c.Threshold
+	}
+	return DefaultCritical
+}
+
+// ApplyThresholds sets the warning and critical thresholds of each target.
+// Thresholds already set on a target (e.g. from a domain list) win, then
+// domain overrides, then the most specific zone override, then the first
+// matching tag override, then the global thresholds. The warning and
+// critical thresholds are resolved independently; a critical threshold
+// above the resolved warning threshold is lowered to it.
+func (c *Config) ApplyThresholds(targets []models.Target) {
+	for i := range targets {
+		warning, critical := c.thresholds(targets[i])
+		if targets[i].Threshold == 0 {
+			targets[i].Threshold = warning
+		}
+		if targets[i].Critical == 0 {
+			targets[i].Critical = critical
+		}
+		if targets[i].Critical > targets[i].Threshold {
+			targets[i].Critical = targets[i].Threshold
+		}
+	}
+}
+
+// thresholds resolves the configured thresholds of a target
+func (c *Config) thresholds(target models.Target) (warning, critical int) {
+	domain := provider.NormalizeName(target.Domain)
+
+	var byDomain, byZone, byTag []ThresholdOverride
+	for _, o := range c.Overrides {
+		switch {
+		case o.Domain != "":
+			if provider.NormalizeName(o.Domain) == domain {
+				byDomain = append(byDomain, o)
+			}
+		case o.Zone != "":
+			zone := provider.NormalizeName(o.Zone)
+			if domain == zone || strings.HasSuffix(domain, "."+zone) {
+				byZone = append(byZone, o)
+			}
+		case o.Tag != "":
+			if hasTag(target.Tags, o.Tag) {
+				byTag = append(byTag, o)
+			}
+		}
+	}
+
+	// Longer zones are more specific
+	sort.SliceStable(byZone, func(i, j int) bool {
+		return len(provider.NormalizeName(byZone[i].Zone)) > len(provider.NormalizeName(byZone[j].Zone))
+	})
+
+	// Walk from the least to the most specific override
+	candidates := append(append(byDomain, byZone...), byTag...)
+	warning, critical = c.Threshold, c.CriticalThreshold()
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if candidates[i].Threshold > 0 {
+			warning = candidates[i].Threshold
+		}
+		if candidates[i].Critical > 0 {
+			critical = candidates[i].Critical
+		}
+	}
+	return warning, critical
+}
+
+// hasTag reports whether tags contains tag, ignoring case
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/pkg/models"
+)
+
+// Source is a provider instance that domains are discovered from
+type Source struct {
+	// Instance is the configured name of the provider instance
+	Instance string
+	// Provider is the DNS provider to query
+	Provider provider.DNSProvider
+	// Zones restricts discovery to the given zones (empty = all zones)
+	Zones []string
+}
+
+// Discover queries all sources concurrently and returns the de-duplicated
+// targets. When the same hostname and port is reported by several sources,
+// the first source in the list wins and the notes of all sources are kept.
+// Sources that fail are reported in the returned error alongside the
+// targets of the sources that succeeded.
+func Discover(ctx context.Context, sources []Source) ([]models.Target, error) {
+	results := make([][]models.Target, len(sources))
+	errs := make([]error, len(sources))
+
+	var wg sync.WaitGroup
+	for i, source := range sources {
+		wg.Add(1)
+		go func(i int, source Source) {
+			defer wg.Done()
+			results[i], errs[i] = discoverSource(ctx, source)
+		}(i, source)
+	}
+	wg.Wait()
+
+	seen := make(map[string]int)
+	targets := make([]models.Target, 0)
+	for _, sourceTargets := range results {
+		for _, target := range sourceTargets {
+			target.Domain = provider.NormalizeName(target.Domain)
+			if target.Domain == "" {
+				continue
+			}
+			key := targetKey(target)
+			if i, ok := seen[key]; ok {
+				targets[i].Notes = mergeNotes(targets[i].Notes, target.Notes)
+				continue
+			}
+			seen[key] = len(targets)
+			targets = append(targets, target)
+		}
+	}
+
+	return targets, errors.Join(errs...)
+}
+
+// discoverSource fetches the targets of a single source
+func discoverSource(ctx context.Context, source Source) ([]models.Target, error) {
+	targets, err := fetchTargets(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", source.Instance, err)
+	}
+
+	for i := range targets {
+		targets[i].Provider = source.Provider.Name()
+		targets[i].Instance = source.Instance
+	}
+
+	return targets, nil
+}
+
+// fetchTargets queries the provider for all zones or the configured ones
+func fetchTargets(ctx context.Context, source Source) ([]models.Target, error) {
+	if tp, ok := source.Provider.(provider.TargetProvider); ok {
+		if len(source.Zones) == 0 {
+			return tp.GetTargets(ctx)
+		}
+		targets := make([]models.Target, 0)
+		for _, zone := range source.Zones {
+			found, err := tp.GetTargetsByZone(ctx, zone)
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, found...)
+		}
+		return targets, nil
+	}
+
+	var domains []string
+	if len(source.Zones) == 0 {
+		found, err := source.Provider.GetDomains(ctx)
+		if err != nil {
+			return nil, err
+		}
+		domains = found
+	} else {
+		for _, zone := range source.Zones {
+			found, err := source.Provider.GetDomainsByZone(ctx, zone)
+			if err != nil {
+				return nil, err
+			}
+			domains = append(domains, found...)
+		}
+	}
+
+	return models.TargetsFromDomains(domains), nil
+}
+
+// mergeNotes appends the notes missing from existing
+func mergeNotes(existing, notes []string) []string {
+	for _, note := range notes {
+		found := false
+		for _, e := range existing {
+			if e == note {
+				found = true
+				break
+			}
+		}
+		if !found {
+			existing = append(existing, note)
+		}
+	}
+	return existing
+}
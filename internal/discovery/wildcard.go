@@ -0,0 +1,155 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/ct"
+	"sslcheckdomain/pkg/models"
+)
+
+// WildcardExpander maps wildcard records to concrete hostnames, so that the
+// certificate served for a wildcard record is still checked
+type WildcardExpander struct {
+	// Hosts lists concrete hostnames per wildcard record
+	Hosts map[string][]string
+	// SampleLabel, when set, is prepended to the base of every wildcard
+	// (e.g. "sslcheck" turns *.example.com into sslcheck.example.com)
+	SampleLabel string
+	// CT, when set, looks up concrete hostnames in Certificate Transparency logs
+	CT *ct.Client
+	// Skipped lists the wildcards of the last Expand call that had no
+	// concrete hostname and were dropped
+	Skipped []string
+
+	ctNames map[string][]string
+}
+
+// NewWildcardExpander creates an expander from static host lists, a sample
+// label and an optional CT search client
+func NewWildcardExpander(hosts map[string][]string, sampleLabel string, ctClient *ct.Client) *WildcardExpander {
+	normalized := make(map[string][]string, len(hosts))
+	for record, names := range hosts {
+		record = provider.NormalizeName(record)
+		normalized[record] = append(normalized[record], names...)
+	}
+
+	return &WildcardExpander{
+		Hosts:       normalized,
+		SampleLabel: strings.Trim(sampleLabel, "."),
+		CT:          ctClient,
+		ctNames:     make(map[string][]string),
+	}
+}
+
+// Expand replaces wildcard targets with the concrete hostnames configured
+// for them. Expanded targets keep the port, secret and origin of the
+// wildcard target; hostnames that are already targets are not repeated.
+// Wildcards without any concrete hostname are dropped and listed in Skipped.
+func (e *WildcardExpander) Expand(ctx context.Context, targets []models.Target) ([]models.Target, error) {
+	e.Skipped = nil
+	skipped := make(map[string]bool)
+	seen := make(map[string]bool, len(targets))
+	for _, target := range targets {
+		seen[targetKey(target)] = true
+	}
+
+	var errs []error
+	expanded := make([]models.Target, 0, len(targets))
+	for _, target := range targets {
+		if !provider.IsWildcard(target.Domain) {
+			expanded = append(expanded, target)
+			continue
+		}
+
+		hosts, err := e.hosts(ctx, provider.NormalizeName(target.Domain))
+		if err != nil {
+			errs = append(errs, err)
+		}
+		if len(hosts) == 0 {
+			if wildcard := provider.NormalizeName(target.Domain); !skipped[wildcard] {
+				skipped[wildcard] = true
+				e.Skipped = append(e.Skipped, wildcard)
+			}
+			continue
+		}
+
+		for _, host := range hosts {
+			concrete := target
+			concrete.Domain = provider.NormalizeName(host)
+			concrete.Wildcard = provider.NormalizeName(target.Domain)
+
+			key := targetKey(concrete)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			expanded = append(expanded, concrete)
+		}
+	}
+
+	if len(errs) > 0 {
+		return expanded, fmt.Errorf("wildcard expansion: %w", errs[0])
+	}
+	return expanded, nil
+}
+
+// hosts returns the concrete hostnames of a wildcard record
+func (e *WildcardExpander) hosts(ctx context.Context, wildcard string) ([]string, error) {
+	base := strings.TrimPrefix(wildcard, "*.")
+	hosts := append([]string{}, e.Hosts[wildcard]...)
+
+	if e.SampleLabel != "" {
+		hosts = append(hosts, e.SampleLabel+"."+base)
+	}
+
+	if e.CT != nil {
+		names, err := e.ctHosts(ctx, base)
+		if err != nil {
+			return hosts, err
+		}
+		hosts = append(hosts, names...)
+	}
+
+	return hosts, nil
+}
+
+// ctHosts returns the names logged exactly one label below base
+func (e *WildcardExpander) ctHosts(ctx context.Context, base string) ([]string, error) {
+	if names, ok := e.ctNames[base]; ok {
+		return names, nil
+	}
+
+	entries, err := e.CT.Search(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	unique := make(map[string]bool)
+	names := make([]string, 0)
+	for _, entry := range entries {
+		if entry.Expired(now) {
+			continue
+		}
+		for _, name := range entry.Names() {
+			label := strings.TrimSuffix(name, "."+base)
+			if label == name || label == "" || strings.ContainsAny(label, ".*") || unique[name] {
+				continue
+			}
+			unique[name] = true
+			names = append(names, name)
+		}
+	}
+
+	e.ctNames[base] = names
+	return names, nil
+}
+
+// targetKey identifies a target by hostname and port
+func targetKey(target models.Target) string {
+	return fmt.Sprintf("%s:%d", provider.NormalizeName(target.Domain), target.Port)
+}
@@ -0,0 +1,384 @@
+// Package inventory reads check targets from domain list files.
+//
+// Four formats are understood:
+//
+//   - lines: one target per line as "host[:port] [key=value ...]" where the
+//     keys are sni, tags (comma separated), threshold and critical. Text
+//     after " #" is a comment.
+//   - csv: a header row naming the columns domain, port, sni, tags,
+//     threshold and critical, followed by one target per row. Only domain
+//     is required.
+//   - json: an array of targets, or an object with a "targets" array. Each
+//     target is either a string in the lines syntax or an object with the
+//     keys domain, port, sni, tags, threshold and critical.
+//   - yaml: the same structure as json.
+//
+// Lines starting with "#" and blank lines are ignored in the lines and csv
+// formats.
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+	"sslcheckdomain/pkg/models"
+)
+
+// Supported inventory formats
+const (
+	FormatLines = "lines"
+	FormatCSV   = "csv"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+)
+
+// entry is a target as written in structured inventories
+type entry struct {
+	Domain    string   `mapstructure:"domain"`
+	Port      int      `mapstructure:"port"`
+	SNI       string   `mapstructure:"sni"`
+	Tags      []string `mapstructure:"tags"`
+	Threshold int      `mapstructure:"threshold"`
+	Critical  int      `mapstructure:"critical"`
+}
+
+// Load reads targets from a file, or from stdin when path is "-".
+// The format is derived from the file extension, or detected from the
+// content when the extension is not recognized.
+func Load(path string) ([]models.Target, error) {
+	if path == "-" {
+		return Parse(os.Stdin, "")
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open domain list: %w", err)
+	}
+	defer f.Close()
+
+	return Parse(f, formatFromExtension(path))
+}
+
+// Parse reads targets in the given format; an empty format is detected
+// from the content.
+func Parse(r io.Reader, format string) ([]models.Target, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read domain list: %w", err)
+	}
+
+	if format == "" {
+		format = detectFormat(data)
+	}
+
+	switch format {
+	case FormatLines:
+		return parseLines(data)
+	case FormatCSV:
+		return parseCSV(data)
+	case FormatJSON:
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid JSON domain list: %w", err)
+		}
+		return parseDocument(doc)
+	case FormatYAML:
+		var doc interface{}
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("invalid YAML domain list: %w", err)
+		}
+		return parseDocument(doc)
+	default:
+		return nil, fmt.Errorf("unsupported domain list format: %s", format)
+	}
+}
+
+// formatFromExtension maps well-known file extensions to formats
+func formatFromExtension(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	default:
+		return ""
+	}
+}
+
+// detectFormat guesses the format from the first meaningful line
+func detectFormat(data []byte) string {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return FormatJSON
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case line == "---", strings.HasPrefix(line, "- "), strings.HasPrefix(line, "targets:"):
+			return FormatYAML
+		case strings.HasPrefix(strings.ToLower(line), "domain,"):
+			return FormatCSV
+		default:
+			return FormatLines
+		}
+	}
+
+	return FormatLines
+}
+
+// parseLines reads the plain "host[:port] key=value" format
+func parseLines(data []byte) ([]models.Target, error) {
+	targets := make([]models.Target, 0)
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		target, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+		targets = append(targets, target)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read domain list: %w", err)
+	}
+
+	return targets, nil
+}
+
+// ParseTarget parses a single "host[:port] key=value ..." entry of the
+// lines format
+func ParseTarget(entry string) (models.Target, error) {
+	return parseLine(entry)
+}
+
+// parseLine parses a single "host[:port] key=value ..." entry
+func parseLine(line string) (models.Target, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return models.Target{}, fmt.Errorf("empty entry")
+	}
+
+	e := entry{Domain: fields[0]}
+	for _, field := range fields[1:] {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return models.Target{}, fmt.Errorf("invalid option %q (expected key=value)", field)
+		}
+
+		switch strings.ToLower(key) {
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil {
+				return models.Target{}, fmt.Errorf("invalid port %q", value)
+			}
+			e.Port = port
+		case "sni":
+			e.SNI = value
+		case "tags":
+			e.Tags = splitTags(value)
+		case "threshold":
+			threshold, err := strconv.Atoi(value)
+			if err != nil {
+				return models.Target{}, fmt.Errorf("invalid threshold %q", value)
+			}
+			e.Threshold = threshold
+		case "critical":
+			critical, err := strconv.Atoi(value)
+			if err != nil {
+				return models.Target{}, fmt.Errorf("invalid critical threshold %q", value)
+			}
+			e.Critical = critical
+		default:
+			return models.Target{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	return e.target()
+}
+
+// parseCSV reads a CSV inventory with a header row
+func parseCSV(data []byte) ([]models.Target, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["domain"]; !ok {
+		return nil, fmt.Errorf("CSV header must contain a domain column")
+	}
+
+	targets := make([]models.Target, 0)
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV domain list: %w", err)
+		}
+
+		row := make(map[string]interface{}, len(columns))
+		for name, i := range columns {
+			if i < len(record) && strings.TrimSpace(record[i]) != "" {
+				row[name] = strings.TrimSpace(record[i])
+			}
+		}
+
+		line, _ := reader.FieldPos(0)
+		target, err := decodeEntry(row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// parseDocument reads a decoded JSON or YAML inventory
+func parseDocument(doc interface{}) ([]models.Target, error) {
+	if object, ok := doc.(map[string]interface{}); ok {
+		doc = object["targets"]
+	}
+	if doc == nil {
+		return []models.Target{}, nil
+	}
+
+	items, ok := doc.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("domain list must be an array of targets")
+	}
+
+	targets := make([]models.Target, 0, len(items))
+	for i, item := range items {
+		var (
+			target models.Target
+			err    error
+		)
+		switch v := item.(type) {
+		case string:
+			target, err = parseLine(v)
+		case map[string]interface{}:
+			target, err = decodeEntry(v)
+		default:
+			err = fmt.Errorf("unexpected value %v", item)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("target %d: %w", i+1, err)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// decodeEntry converts a structured entry to a target
+func decodeEntry(values map[string]interface{}) (models.Target, error) {
+	var e entry
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToSliceHookFunc(","),
+		WeaklyTypedInput: true,
+		ErrorUnused:      true,
+		Result:           &e,
+	})
+	if err != nil {
+		return models.Target{}, err
+	}
+	if err := decoder.Decode(values); err != nil {
+		return models.Target{}, err
+	}
+	return e.target()
+}
+
+// target validates the entry and converts it to a target
+func (e entry) target() (models.Target, error) {
+	domain := strings.TrimSpace(e.Domain)
+	if domain == "" {
+		return models.Target{}, fmt.Errorf("domain is required")
+	}
+
+	// Accept host:port in the domain field
+	if host, port, err := net.SplitHostPort(domain); err == nil {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			return models.Target{}, fmt.Errorf("invalid port in %q", domain)
+		}
+		if e.Port != 0 && e.Port != p {
+			return models.Target{}, fmt.Errorf("conflicting ports for %q", domain)
+		}
+		domain, e.Port = host, p
+	}
+
+	if e.Port < 0 || e.Port > 65535 {
+		return models.Target{}, fmt.Errorf("port out of range: %d", e.Port)
+	}
+	if e.Threshold < 0 || e.Critical < 0 {
+		return models.Target{}, fmt.Errorf("thresholds must be non-negative")
+	}
+
+	return models.Target{
+		Domain:    strings.TrimSuffix(strings.ToLower(domain), "."),
+		Port:      e.Port,
+		SNI:       e.SNI,
+		Tags:      cleanTags(e.Tags),
+		Threshold: e.Threshold,
+		Critical:  e.Critical,
+	}, nil
+}
+
+// splitTags splits a comma separated tag list
+func splitTags(value string) []string {
+	return cleanTags(strings.Split(value, ","))
+}
+
+// cleanTags trims tags and drops empty ones
+func cleanTags(tags []string) []string {
+	cleaned := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			cleaned = append(cleaned, tag)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil
+	}
+	return cleaned
+}
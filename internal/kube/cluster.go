@@ -0,0 +1,146 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// defaultTimeout bounds each request to the API server
+const defaultTimeout = 30 * time.Second
+
+// listPageSize is the number of objects requested per page
+const listPageSize = 500
+
+// errNotFound is returned when the API server does not serve a resource
+var errNotFound = errors.New("resource not found")
+
+// Cluster lists objects from a live API server
+type Cluster struct {
+	config    *restConfig
+	client    *http.Client
+	namespace string
+}
+
+// NewCluster connects to the cluster of a kubeconfig context. An empty path
+// follows kubectl's lookup ($KUBECONFIG, ~/.kube/config, in-cluster), an
+// empty context uses the current context and an empty namespace lists
+// objects of all namespaces.
+func NewCluster(kubeconfigPath, contextName, namespace string) (*Cluster, error) {
+	cfg, err := loadRestConfig(kubeconfigPath, contextName)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.server == "" {
+		return nil, fmt.Errorf("kubeconfig has no server address")
+	}
+
+	return &Cluster{
+		config:    cfg,
+		client:    cfg.httpClient(defaultTimeout),
+		namespace: namespace,
+	}, nil
+}
+
+// listResponse is a page of a list request
+type listResponse struct {
+	Metadata struct {
+		Continue string `json:"continue"`
+	} `json:"metadata"`
+	Items []Object `json:"items"`
+}
+
+// List returns all objects of a resource, following pagination
+func (c *Cluster) List(ctx context.Context, resource Resource) ([]Object, error) {
+	for _, version := range resource.Versions {
+		objects, err := c.list(ctx, resource, version)
+		if err == errNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", resource.Plural, err)
+		}
+		return objects, nil
+	}
+
+	// None of the versions is served, e.g. the CRD is not installed
+	return []Object{}, nil
+}
+
+// list lists a resource at a specific version
+func (c *Cluster) list(ctx context.Context, resource Resource, version string) ([]Object, error) {
+	path := "/apis/" + resource.Group + "/" + version
+	if resource.Group == "" {
+		path = "/api/" + version
+	}
+	if c.namespace != "" {
+		path += "/namespaces/" + url.PathEscape(c.namespace)
+	}
+	path += "/" + resource.Plural
+
+	objects := make([]Object, 0)
+	continueToken := ""
+	for {
+		query := url.Values{"limit": {fmt.Sprint(listPageSize)}}
+		if continueToken != "" {
+			query.Set("continue", continueToken)
+		}
+
+		var page listResponse
+		if err := c.get(ctx, path+"?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+
+		// Items of a list omit apiVersion and kind
+		for _, item := range page.Items {
+			item.APIVersion = version
+			if resource.Group != "" {
+				item.APIVersion = resource.Group + "/" + version
+			}
+			item.Kind = resource.Kind
+			objects = append(objects, item)
+		}
+
+		continueToken = page.Metadata.Continue
+		if continueToken == "" {
+			return objects, nil
+		}
+	}
+}
+
+// get performs a GET request against the API server and decodes the body
+func (c *Cluster) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.config.server+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if err := c.config.authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.config.exec != nil {
+		// Fetch a new credential next time, e.g. after a revoked token
+		c.config.exec.expire()
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("API server returned %s: %s", resp.Status, body)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
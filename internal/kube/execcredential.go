@@ -0,0 +1,209 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// execTimeout bounds a single run of a credential plugin
+const execTimeout = 60 * time.Second
+
+// execRefreshMargin renews credentials shortly before they expire, so they
+// do not run out during a request
+const execRefreshMargin = 30 * time.Second
+
+// execAPIVersions are the ExecCredential versions plugins may use
+var execAPIVersions = []string{
+	"client.authentication.k8s.io/v1",
+	"client.authentication.k8s.io/v1beta1",
+}
+
+// execConfig is the exec section of a kubeconfig user, running a credential
+// plugin such as aws, gke-gcloud-auth-plugin or kubelogin
+type execConfig struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Command    string   `yaml:"command"`
+	Args       []string `yaml:"args"`
+	Env        []struct {
+		Name  string `yaml:"name"`
+		Value string `yaml:"value"`
+	} `yaml:"env"`
+	InstallHint        string `yaml:"installHint"`
+	ProvideClusterInfo bool   `yaml:"provideClusterInfo"`
+	InteractiveMode    string `yaml:"interactiveMode"`
+}
+
+// execCluster is the cluster passed to plugins asking for it
+type execCluster struct {
+	Server                   string `json:"server"`
+	TLSServerName            string `json:"tls-server-name,omitempty"`
+	InsecureSkipTLSVerify    bool   `json:"insecure-skip-tls-verify,omitempty"`
+	CertificateAuthorityData []byte `json:"certificate-authority-data,omitempty"`
+}
+
+// execCredential is the ExecCredential object exchanged with plugins
+type execCredential struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Interactive bool         `json:"interactive"`
+		Cluster     *execCluster `json:"cluster,omitempty"`
+	} `json:"spec"`
+	Status *execStatus `json:"status,omitempty"`
+}
+
+// execStatus is the credential returned by a plugin
+type execStatus struct {
+	ExpirationTimestamp   *time.Time `json:"expirationTimestamp,omitempty"`
+	Token                 string     `json:"token,omitempty"`
+	ClientCertificateData string     `json:"clientCertificateData,omitempty"`
+	ClientKeyData         string     `json:"clientKeyData,omitempty"`
+}
+
+// execPlugin runs a credential plugin and caches its credential until it
+// expires
+type execPlugin struct {
+	config  execConfig
+	cluster *execCluster
+
+	mu   sync.Mutex
+	cred *execStatus
+	cert *tls.Certificate
+}
+
+// newExecPlugin checks the exec section of a kubeconfig user. cluster is
+// passed to the plugin when it asks for it.
+func newExecPlugin(config execConfig, cluster *execCluster) (*execPlugin, error) {
+	if config.Command == "" {
+		return nil, fmt.Errorf("exec plugin has no command")
+	}
+
+	supported := false
+	for _, version := range execAPIVersions {
+		if config.APIVersion == version {
+			supported = true
+		}
+	}
+	if !supported {
+		return nil, fmt.Errorf("exec plugin %s uses unsupported apiVersion %q (supported: %s)",
+			config.Command, config.APIVersion, strings.Join(execAPIVersions, ", "))
+	}
+
+	// Credentials are fetched in the background, where nobody can answer
+	// prompts
+	if config.InteractiveMode == "Always" {
+		return nil, fmt.Errorf("exec plugin %s requires an interactive terminal", config.Command)
+	}
+
+	p := &execPlugin{config: config}
+	if config.ProvideClusterInfo {
+		p.cluster = cluster
+	}
+	return p, nil
+}
+
+// credential returns the cached credential, running the plugin when there
+// is none or it is about to expire
+func (p *execPlugin) credential(ctx context.Context) (*execStatus, *tls.Certificate, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cred != nil && (p.cred.ExpirationTimestamp == nil || time.Until(*p.cred.ExpirationTimestamp) > execRefreshMargin) {
+		return p.cred, p.cert, nil
+	}
+
+	cred, err := p.run(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var cert *tls.Certificate
+	if cred.ClientCertificateData != "" {
+		pair, err := tls.X509KeyPair([]byte(cred.ClientCertificateData), []byte(cred.ClientKeyData))
+		if err != nil {
+			return nil, nil, fmt.Errorf("exec plugin %s returned an invalid client certificate: %w", p.config.Command, err)
+		}
+		cert = &pair
+	}
+
+	p.cred, p.cert = cred, cert
+	return cred, cert, nil
+}
+
+// expire drops the cached credential, e.g. after the API server rejected it
+func (p *execPlugin) expire() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cred, p.cert = nil, nil
+}
+
+// run executes the plugin and parses the ExecCredential it prints
+func (p *execPlugin) run(ctx context.Context) (*execStatus, error) {
+	request := execCredential{APIVersion: p.config.APIVersion, Kind: "ExecCredential"}
+	request.Spec.Cluster = p.cluster
+	info, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, execTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, p.config.Command, p.config.Args...)
+	cmd.Env = append(os.Environ(), "KUBERNETES_EXEC_INFO="+string(info))
+	for _, env := range p.config.Env {
+		cmd.Env = append(cmd.Env, env.Name+"="+env.Value)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) && p.config.InstallHint != "" {
+			return nil, fmt.Errorf("exec plugin %s not found: %s", p.config.Command, strings.TrimSpace(p.config.InstallHint))
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("exec plugin %s failed: %w: %s", p.config.Command, err, msg)
+		}
+		return nil, fmt.Errorf("exec plugin %s failed: %w", p.config.Command, err)
+	}
+
+	var response execCredential
+	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
+		return nil, fmt.Errorf("exec plugin %s printed an invalid ExecCredential: %w", p.config.Command, err)
+	}
+	if response.APIVersion != p.config.APIVersion || response.Kind != "ExecCredential" {
+		return nil, fmt.Errorf("exec plugin %s returned %s %s, want ExecCredential %s",
+			p.config.Command, response.APIVersion, response.Kind, p.config.APIVersion)
+	}
+	if response.Status == nil || (response.Status.Token == "" && response.Status.ClientCertificateData == "") {
+		return nil, fmt.Errorf("exec plugin %s returned no token or client certificate", p.config.Command)
+	}
+	if (response.Status.ClientCertificateData == "") != (response.Status.ClientKeyData == "") {
+		return nil, fmt.Errorf("exec plugin %s returned a client certificate without its key", p.config.Command)
+	}
+	return response.Status, nil
+}
+
+// clientCertificate provides the client certificate of the plugin during the
+// TLS handshake, an empty one when the plugin only returns tokens
+func (p *execPlugin) clientCertificate(info *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	_, cert, err := p.credential(info.Context())
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return &tls.Certificate{}, nil
+	}
+	return cert, nil
+}
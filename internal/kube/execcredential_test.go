@@ -0,0 +1,135 @@
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// pluginScript prints an ExecCredential with the token in $TOKEN, records
+// each run in runs.log and saves KUBERNETES_EXEC_INFO to info.json
+const pluginScript = `#!/bin/sh
+dir=$(dirname "$0")
+echo run >> "$dir/runs.log"
+printf '%s' "$KUBERNETES_EXEC_INFO" > "$dir/info.json"
+printf '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential","status":{"token":"%s","expirationTimestamp":"2999-01-01T00:00:00Z"}}' "$TOKEN"
+`
+
+// writeKubeconfig writes a kubeconfig for server whose user runs the plugin
+// script from the kubeconfig directory, and returns its path
+func writeKubeconfig(t *testing.T, server string) string {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "plugin.sh"), []byte(pluginScript), 0o755); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+
+	kubeconfig := `apiVersion: v1
+kind: Config
+current-context: test
+clusters:
+  - name: test
+    cluster:
+      server: ` + server + `
+contexts:
+  - name: test
+    context: {cluster: test, user: plugin}
+users:
+  - name: plugin
+    user:
+      exec:
+        apiVersion: client.authentication.k8s.io/v1
+        command: ./plugin.sh
+        env:
+          - {name: TOKEN, value: plugin-token}
+        provideClusterInfo: true
+        interactiveMode: Never
+`
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(kubeconfig), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestExecCredentialPlugin(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer plugin-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"metadata":{},"items":[{"metadata":{"name":"web","namespace":"shop"}}]}`))
+	}))
+	defer server.Close()
+
+	path := writeKubeconfig(t, server.URL)
+	cluster, err := NewCluster(path, "", "")
+	if err != nil {
+		t.Fatalf("NewCluster() error = %v", err)
+	}
+
+	// The credential is cached across requests
+	for i := 0; i < 2; i++ {
+		objects, err := cluster.List(context.Background(), Ingresses)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if len(objects) != 1 || objects[0].Key() != "shop/web" {
+			t.Fatalf("List() = %+v, want shop/web", objects)
+		}
+	}
+
+	runs, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "runs.log"))
+	if n := strings.Count(string(runs), "run"); n != 1 {
+		t.Errorf("plugin ran %d times, want 1", n)
+	}
+
+	var info execCredential
+	data, _ := os.ReadFile(filepath.Join(filepath.Dir(path), "info.json"))
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("invalid KUBERNETES_EXEC_INFO %q: %v", data, err)
+	}
+	if info.Kind != "ExecCredential" || info.Spec.Interactive || info.Spec.Cluster == nil || info.Spec.Cluster.Server != server.URL {
+		t.Errorf("KUBERNETES_EXEC_INFO = %s, want the cluster of the context", data)
+	}
+}
+
+func TestExecCredentialErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config execConfig
+		want   string
+	}{
+		{"alpha version", execConfig{APIVersion: "client.authentication.k8s.io/v1alpha1", Command: "aws"}, "unsupported apiVersion"},
+		{"interactive", execConfig{APIVersion: execAPIVersions[0], Command: "kubelogin", InteractiveMode: "Always"}, "interactive"},
+		{"no command", execConfig{APIVersion: execAPIVersions[0]}, "no command"},
+	}
+	for _, tt := range tests {
+		if _, err := newExecPlugin(tt.config, nil); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: newExecPlugin() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+
+	plugin, err := newExecPlugin(execConfig{
+		APIVersion:  execAPIVersions[0],
+		Command:     "sslcheckdomain-missing-plugin",
+		InstallHint: "Install it from example.test",
+	}, nil)
+	if err != nil {
+		t.Fatalf("newExecPlugin() error = %v", err)
+	}
+	if _, _, err := plugin.credential(context.Background()); err == nil || !strings.Contains(err.Error(), "Install it from example.test") {
+		t.Errorf("credential() error = %v, want the install hint", err)
+	}
+}
@@ -0,0 +1,99 @@
+// Package kube reads Kubernetes objects either from a live cluster or from
+// exported manifests, without depending on the Kubernetes client libraries.
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// DefaultNamespace is the namespace of objects that do not name one
+const DefaultNamespace = "default"
+
+// Resource identifies a kind of object to list
+type Resource struct {
+	// Group is the API group, empty for the core group
+	Group string
+	// Versions are tried in order until the API server knows one of them
+	Versions []string
+	Kind     string
+	// Plural is the resource name used in API paths
+	Plural string
+}
+
+// Common resources
+var (
+	Ingresses    = Resource{Group: "networking.k8s.io", Versions: []string{"v1"}, Kind: "Ingress", Plural: "ingresses"}
+	Gateways     = Resource{Group: "gateway.networking.k8s.io", Versions: []string{"v1", "v1beta1"}, Kind: "Gateway", Plural: "gateways"}
+	HTTPRoutes   = Resource{Group: "gateway.networking.k8s.io", Versions: []string{"v1", "v1beta1"}, Kind: "HTTPRoute", Plural: "httproutes"}
+	Certificates = Resource{Group: "cert-manager.io", Versions: []string{"v1"}, Kind: "Certificate", Plural: "certificates"}
+	Secrets      = Resource{Versions: []string{"v1"}, Kind: "Secret", Plural: "secrets"}
+)
+
+// Source lists Kubernetes objects
+type Source interface {
+	// List returns all objects of a resource. Resources the source does
+	// not know about (e.g. a CRD that is not installed) yield no objects.
+	List(ctx context.Context, resource Resource) ([]Object, error)
+}
+
+// Metadata holds the object metadata used by this tool
+type Metadata struct {
+	Name      string            `json:"name"`
+	Namespace string            `json:"namespace"`
+	Labels    map[string]string `json:"labels,omitempty"`
+}
+
+// Object is a Kubernetes object whose body is decoded on demand
+type Object struct {
+	APIVersion string   `json:"apiVersion"`
+	Kind       string   `json:"kind"`
+	Metadata   Metadata `json:"metadata"`
+
+	raw json.RawMessage
+}
+
+// UnmarshalJSON keeps the raw object so that Decode can read any field
+func (o *Object) UnmarshalJSON(data []byte) error {
+	type header Object
+	var h header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return err
+	}
+	*o = Object(h)
+	o.raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// Decode decodes the full object into v
+func (o Object) Decode(v interface{}) error {
+	if err := json.Unmarshal(o.raw, v); err != nil {
+		return fmt.Errorf("failed to decode %s %s: %w", o.Kind, o.Key(), err)
+	}
+	return nil
+}
+
+// Namespace returns the namespace of the object. Manifests may omit it, in
+// which case kubectl would apply them to DefaultNamespace.
+func (o Object) Namespace() string {
+	if o.Metadata.Namespace == "" {
+		return DefaultNamespace
+	}
+	return o.Metadata.Namespace
+}
+
+// Key returns the namespace/name of the object
+func (o Object) Key() string {
+	return o.Namespace() + "/" + o.Metadata.Name
+}
+
+// group returns the API group of the object
+func (o Object) group() string {
+	group, _, ok := strings.Cut(o.APIVersion, "/")
+	if !ok {
+		return ""
+	}
+	return group
+}
@@ -0,0 +1,288 @@
+package kube
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// In-cluster service account locations
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+)
+
+// restConfig holds what is needed to talk to an API server
+type restConfig struct {
+	server   string
+	token    string
+	username string
+	password string
+	tls      *tls.Config
+	exec     *execPlugin
+}
+
+// kubeconfig is the subset of the kubeconfig file format used here
+type kubeconfig struct {
+	CurrentContext string `yaml:"current-context"`
+	Clusters       []struct {
+		Name    string `yaml:"name"`
+		Cluster struct {
+			Server                   string `yaml:"server"`
+			CertificateAuthority     string `yaml:"certificate-authority"`
+			CertificateAuthorityData string `yaml:"certificate-authority-data"`
+			InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+			TLSServerName            string `yaml:"tls-server-name"`
+		} `yaml:"cluster"`
+	} `yaml:"clusters"`
+	Users []struct {
+		Name string `yaml:"name"`
+		User struct {
+			Token                 string      `yaml:"token"`
+			TokenFile             string      `yaml:"tokenFile"`
+			ClientCertificate     string      `yaml:"client-certificate"`
+			ClientCertificateData string      `yaml:"client-certificate-data"`
+			ClientKey             string      `yaml:"client-key"`
+			ClientKeyData         string      `yaml:"client-key-data"`
+			Username              string      `yaml:"username"`
+			Password              string      `yaml:"password"`
+			Exec                  *execConfig `yaml:"exec"`
+			AuthProvider          *struct {
+				Name string `yaml:"name"`
+			} `yaml:"auth-provider"`
+		} `yaml:"user"`
+	} `yaml:"users"`
+	Contexts []struct {
+		Name    string `yaml:"name"`
+		Context struct {
+			Cluster string `yaml:"cluster"`
+			User    string `yaml:"user"`
+		} `yaml:"context"`
+	} `yaml:"contexts"`
+}
+
+// kubeconfigPath returns the kubeconfig to use, following kubectl's lookup
+func kubeconfigPath(path string) string {
+	if env := os.Getenv("KUBECONFIG"); path == "" && env != "" {
+		path = filepath.SplitList(env)[0]
+	}
+	if path == "" {
+		path = "~/.kube/config"
+	}
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		path = filepath.Join(home, path[2:])
+	}
+	return path
+}
+
+// loadRestConfig loads a kubeconfig, falling back to the in-cluster service
+// account when no kubeconfig exists and the process runs inside a pod.
+func loadRestConfig(path, contextName string) (*restConfig, error) {
+	path = kubeconfigPath(path)
+	if _, err := os.Stat(path); err != nil {
+		if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
+			return inClusterConfig()
+		}
+		return nil, fmt.Errorf("kubeconfig not found: %s", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read kubeconfig: %w", err)
+	}
+
+	var kc kubeconfig
+	if err := yaml.Unmarshal(data, &kc); err != nil {
+		return nil, fmt.Errorf("failed to parse kubeconfig: %w", err)
+	}
+
+	if contextName == "" {
+		contextName = kc.CurrentContext
+	}
+
+	// Relative paths in a kubeconfig are relative to the file
+	base := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(base, p)
+	}
+
+	for _, c := range kc.Contexts {
+		if c.Name != contextName {
+			continue
+		}
+
+		cfg := &restConfig{tls: &tls.Config{MinVersion: tls.VersionTLS12}}
+		var cluster *execCluster
+
+		found := false
+		for _, cl := range kc.Clusters {
+			if cl.Name != c.Context.Cluster {
+				continue
+			}
+			found = true
+			cfg.server = strings.TrimSuffix(cl.Cluster.Server, "/")
+			cfg.tls.InsecureSkipVerify = cl.Cluster.InsecureSkipTLSVerify
+			cfg.tls.ServerName = cl.Cluster.TLSServerName
+
+			ca, err := readData(cl.Cluster.CertificateAuthorityData, resolve(cl.Cluster.CertificateAuthority))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read certificate authority: %w", err)
+			}
+			if len(ca) > 0 {
+				pool := x509.NewCertPool()
+				if !pool.AppendCertsFromPEM(ca) {
+					return nil, fmt.Errorf("invalid certificate authority for cluster %s", cl.Name)
+				}
+				cfg.tls.RootCAs = pool
+			}
+			cluster = &execCluster{
+				Server:                   cfg.server,
+				TLSServerName:            cl.Cluster.TLSServerName,
+				InsecureSkipTLSVerify:    cl.Cluster.InsecureSkipTLSVerify,
+				CertificateAuthorityData: ca,
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("cluster %q of context %q not found in kubeconfig", c.Context.Cluster, contextName)
+		}
+
+		for _, u := range kc.Users {
+			if u.Name != c.Context.User {
+				continue
+			}
+			if u.User.AuthProvider != nil {
+				return nil, fmt.Errorf("user %q uses the %s auth-provider, which is not supported; use its exec credential plugin instead", u.Name, u.User.AuthProvider.Name)
+			}
+			if u.User.Exec != nil {
+				// Like kubectl, commands with a relative path are
+				// relative to the kubeconfig
+				if strings.ContainsRune(u.User.Exec.Command, filepath.Separator) {
+					u.User.Exec.Command = resolve(u.User.Exec.Command)
+				}
+				plugin, err := newExecPlugin(*u.User.Exec, cluster)
+				if err != nil {
+					return nil, fmt.Errorf("user %q: %w", u.Name, err)
+				}
+				cfg.exec = plugin
+				cfg.tls.GetClientCertificate = plugin.clientCertificate
+			}
+
+			cfg.token = u.User.Token
+			if u.User.TokenFile != "" {
+				token, err := os.ReadFile(resolve(u.User.TokenFile))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read token file: %w", err)
+				}
+				cfg.token = strings.TrimSpace(string(token))
+			}
+			cfg.username = u.User.Username
+			cfg.password = u.User.Password
+
+			certPEM, err := readData(u.User.ClientCertificateData, resolve(u.User.ClientCertificate))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read client certificate: %w", err)
+			}
+			keyPEM, err := readData(u.User.ClientKeyData, resolve(u.User.ClientKey))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read client key: %w", err)
+			}
+			if len(certPEM) > 0 {
+				pair, err := tls.X509KeyPair(certPEM, keyPEM)
+				if err != nil {
+					return nil, fmt.Errorf("invalid client certificate: %w", err)
+				}
+				cfg.tls.Certificates = []tls.Certificate{pair}
+			}
+		}
+
+		return cfg, nil
+	}
+
+	return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+}
+
+// inClusterConfig builds a config from the pod's service account
+func inClusterConfig() (*restConfig, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if port == "" {
+		port = "443"
+	}
+
+	token, err := os.ReadFile(filepath.Join(serviceAccountDir, "token"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read service account token: %w", err)
+	}
+
+	cfg := &restConfig{
+		server: "https://" + net.JoinHostPort(host, port),
+		token:  strings.TrimSpace(string(token)),
+		tls:    &tls.Config{MinVersion: tls.VersionTLS12},
+	}
+
+	if ca, err := os.ReadFile(filepath.Join(serviceAccountDir, "ca.crt")); err == nil {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(ca)
+		cfg.tls.RootCAs = pool
+	}
+
+	return cfg, nil
+}
+
+// readData returns base64 inline data, or the content of file
+func readData(inline, file string) ([]byte, error) {
+	if inline != "" {
+		return base64.StdEncoding.DecodeString(inline)
+	}
+	if file != "" {
+		return os.ReadFile(file)
+	}
+	return nil, nil
+}
+
+// authorize adds the credentials of the config to a request
+func (c *restConfig) authorize(req *http.Request) error {
+	token := c.token
+	if c.exec != nil {
+		cred, _, err := c.exec.credential(req.Context())
+		if err != nil {
+			return err
+		}
+		if cred.Token != "" {
+			token = cred.Token
+		}
+	}
+
+	switch {
+	case token != "":
+		req.Header.Set("Authorization", "Bearer "+token)
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return nil
+}
+
+// httpClient returns the HTTP client for the config
+func (c *restConfig) httpClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: c.tls,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package kube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Manifests lists objects from exported YAML or JSON manifests, such as the
+// output of "kubectl get ingress -A -o yaml", for offline use
+type Manifests struct {
+	namespace string
+	objects   []Object
+}
+
+// LoadManifests reads all .yaml, .yml and .json files in the given files
+// and directories. Multi-document files and List objects are supported.
+// When namespace is set, only the objects of that namespace are listed.
+func LoadManifests(paths []string, namespace string) (*Manifests, error) {
+	m := &Manifests{namespace: namespace}
+
+	for _, root := range paths {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(path)) {
+			case ".yaml", ".yml", ".json":
+			default:
+				// Only skip unknown extensions found while walking directories
+				if path != root {
+					return nil
+				}
+			}
+			return m.loadFile(path)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load manifests: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// loadFile reads every document of a manifest file
+func (m *Manifests) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// YAML is a superset of JSON, so one decoder handles both
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc map[string]interface{}
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if doc == nil {
+			continue
+		}
+		if err := m.add(doc); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+}
+
+// add appends a decoded document, expanding List objects
+func (m *Manifests) add(doc map[string]interface{}) error {
+	if kind, _ := doc["kind"].(string); strings.HasSuffix(kind, "List") {
+		items, _ := doc["items"].([]interface{})
+		for _, item := range items {
+			if object, ok := item.(map[string]interface{}); ok {
+				if err := m.add(object); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+
+	var object Object
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	m.objects = append(m.objects, object)
+	return nil
+}
+
+// List returns the loaded objects of a resource, regardless of version
+func (m *Manifests) List(ctx context.Context, resource Resource) ([]Object, error) {
+	objects := make([]Object, 0)
+	for _, object := range m.objects {
+		if object.Kind != resource.Kind || object.group() != resource.Group {
+			continue
+		}
+		if m.namespace == "" || object.Namespace() == m.namespace {
+			objects = append(objects, object)
+		}
+	}
+	return objects, nil
+}
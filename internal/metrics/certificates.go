@@ -0,0 +1,170 @@
+package metrics
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// StatusValue converts a certificate status to the value of the
+// ssl_certificate_status metric. Checked certificates are ordered by
+// severity, so "< 3" matches every certificate that needs attention; failed
+// checks are kept apart from that scale.
+func StatusValue(status models.CertificateStatus) float64 {
+	switch status {
+	case models.StatusExpired:
+		return 0
+	case models.StatusCritical:
+		return 1
+	case models.StatusWarning:
+		return 2
+	case models.StatusOK:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// Families returns the metric families of a report. Every certificate is
+// identified by its domain, port and the server name sent to it, so checks
+// of one address with different SNI names are exported side by side. A
+// check repeated with the same connection settings, e.g. of a host listed by
+// two provider instances, is exported once.
+func Families(report *models.CertificateReport) []Family {
+	expiry := Family{
+		Name: "ssl_certificate_expiry_days",
+		Help: "Days until SSL certificate expiration, NaN when the check failed",
+		Type: TypeGauge,
+	}
+	status := Family{
+		Name: "ssl_certificate_status",
+		Help: "SSL certificate status (0=expired, 1=critical, 2=warning, 3=ok, 4=error)",
+		Type: TypeGauge,
+	}
+	notAfter := Family{
+		Name: "ssl_certificate_not_after_seconds",
+		Help: "Expiration time of the SSL certificate as unix timestamp",
+		Type: TypeGauge,
+		Unit: "seconds",
+	}
+	success := Family{
+		Name: "ssl_certificate_check_success",
+		Help: "Whether the SSL certificate could be retrieved (1) or not (0)",
+		Type: TypeGauge,
+	}
+	duration := Family{
+		Name: "ssl_certificate_check_duration_seconds",
+		Help: "Duration of the SSL certificate check",
+		Type: TypeGauge,
+		Unit: "seconds",
+	}
+	info := Family{
+		Name: "ssl_certificate",
+		Help: "SSL certificate details",
+		Type: TypeInfo,
+	}
+
+	seen := make(map[string]bool)
+	for _, cert := range report.Certificates {
+		port := cert.Port
+		if port == 0 {
+			port = 443
+		}
+		sni := cert.SNI
+		if sni == "" {
+			sni = cert.Domain
+		}
+		key := cert.Domain + ":" + strconv.Itoa(port) + "/" + sni
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		target := []Label{
+			{Name: "domain", Value: cert.Domain},
+			{Name: "port", Value: strconv.Itoa(port)},
+			{Name: "sni", Value: sni},
+		}
+		failed := cert.Error != nil || cert.Status == models.StatusError
+
+		days := float64(cert.DaysLeft)
+		if failed {
+			days = math.NaN()
+		}
+		expiry.Metrics = append(expiry.Metrics, Metric{
+			Labels: withLabels(target, Label{"issuer", cert.Issuer}, Label{"status", string(cert.Status)}),
+			Value:  days,
+		})
+		status.Metrics = append(status.Metrics, Metric{
+			Labels: withLabels(target, Label{"issuer", cert.Issuer}),
+			Value:  StatusValue(cert.Status),
+		})
+		success.Metrics = append(success.Metrics, Metric{
+			Labels: target,
+			Value:  boolValue(!failed),
+		})
+		duration.Metrics = append(duration.Metrics, Metric{
+			Labels: target,
+			Value:  cert.Duration.Seconds(),
+		})
+
+		if failed {
+			continue
+		}
+		notAfter.Metrics = append(notAfter.Metrics, Metric{
+			Labels: target,
+			Value:  float64(cert.ExpiresAt.Unix()),
+		})
+		info.Metrics = append(info.Metrics, Metric{
+			Labels: withLabels(target,
+				Label{"issuer", cert.Issuer},
+				Label{"subject", cert.Subject},
+				Label{"serial", cert.SerialNumber},
+				Label{"sans", strings.Join(cert.SANs, ",")},
+			),
+			Value: 1,
+		})
+	}
+
+	return append([]Family{expiry, status, notAfter, success, duration, info}, summaryFamilies(report)...)
+}
+
+// summaryFamilies returns the report-wide gauges
+func summaryFamilies(report *models.CertificateReport) []Family {
+	gauge := func(name, help string, value float64) Family {
+		return Family{Name: name, Help: help, Type: TypeGauge, Metrics: []Metric{{Value: value}}}
+	}
+
+	families := []Family{
+		gauge("ssl_certificates_total", "Total number of certificates checked", float64(report.TotalDomains)),
+		gauge("ssl_certificates_expired", "Number of expired certificates", float64(report.Summary.Expired)),
+		gauge("ssl_certificates_critical", "Number of certificates within the critical threshold", float64(report.Summary.Critical)),
+		gauge("ssl_certificates_warning", "Number of certificates with warnings", float64(report.Summary.Warning)),
+		gauge("ssl_certificates_ok", "Number of OK certificates", float64(report.Summary.OK)),
+		gauge("ssl_certificates_error", "Number of certificates with errors", float64(report.Summary.Error)),
+	}
+
+	if !report.Timestamp.IsZero() {
+		last := gauge("ssl_check_last_run_seconds", "Time of the last SSL certificate check as unix timestamp", float64(report.Timestamp.Unix()))
+		last.Unit = "seconds"
+		families = append(families, last)
+	}
+	return families
+}
+
+// withLabels returns base followed by the extra labels
+func withLabels(base []Label, extra ...Label) []Label {
+	labels := make([]Label, 0, len(base)+len(extra))
+	labels = append(labels, base...)
+	return append(labels, extra...)
+}
+
+// boolValue converts a bool to 1 or 0
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
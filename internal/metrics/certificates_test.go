@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"testing"
+
+	"sslcheckdomain/pkg/models"
+)
+
+func TestStatusValueOrder(t *testing.T) {
+	// Alerts like "ssl_certificate_status < 3" rely on this order
+	order := []models.CertificateStatus{
+		models.StatusExpired,
+		models.StatusCritical,
+		models.StatusWarning,
+		models.StatusOK,
+	}
+	for i, status := range order {
+		if got := StatusValue(status); got != float64(i) {
+			t.Errorf("StatusValue(%s) = %v, want %d", status, got, i)
+		}
+	}
+
+	if got := StatusValue(models.StatusError); got != 4 {
+		t.Errorf("StatusValue(error) = %v, want 4", got)
+	}
+}
@@ -0,0 +1,154 @@
+// Package metrics models certificate results as metric families and writes
+// them in the Prometheus text and OpenMetrics exposition formats.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Content types of the exposition formats
+const (
+	ContentTypeText        = "text/plain; version=0.0.4; charset=utf-8"
+	ContentTypeOpenMetrics = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Metric types
+const (
+	TypeGauge = "gauge"
+	TypeInfo  = "info"
+)
+
+// Family is a named group of metrics of the same type
+type Family struct {
+	// Name is the family name; the samples of info families carry an
+	// additional _info suffix
+	Name string
+	Help string
+	Type string
+	// Unit is the OpenMetrics unit, which Name must end with
+	Unit    string
+	Metrics []Metric
+}
+
+// Metric is a sample of a family
+type Metric struct {
+	Labels []Label
+	Value  float64
+}
+
+// Label is a metric label
+type Label struct {
+	Name  string
+	Value string
+}
+
+// sampleName returns the name of the family's samples
+func (f Family) sampleName() string {
+	if f.Type == TypeInfo {
+		return f.Name + "_info"
+	}
+	return f.Name
+}
+
+// WriteText writes the families in the Prometheus text format. Info
+// families are written as gauges, which the text format has no type for.
+func WriteText(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for i, f := range families {
+		if i > 0 {
+			bw.WriteString("\n")
+		}
+		typ := f.Type
+		if typ == TypeInfo {
+			typ = TypeGauge
+		}
+		name := f.sampleName()
+		fmt.Fprintf(bw, "# HELP %s %s\n", name, escapeHelp(f.Help, false))
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, typ)
+		writeSamples(bw, name, f.Metrics)
+	}
+	return flush(bw)
+}
+
+// WriteOpenMetrics writes the families in the OpenMetrics text format,
+// terminated by the # EOF marker
+func WriteOpenMetrics(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		fmt.Fprintf(bw, "# TYPE %s %s\n", f.Name, f.Type)
+		if f.Unit != "" {
+			fmt.Fprintf(bw, "# UNIT %s %s\n", f.Name, f.Unit)
+		}
+		fmt.Fprintf(bw, "# HELP %s %s\n", f.Name, escapeHelp(f.Help, true))
+		writeSamples(bw, f.sampleName(), f.Metrics)
+	}
+	bw.WriteString("# EOF\n")
+	return flush(bw)
+}
+
+// writeSamples writes one line per metric
+func writeSamples(w *bufio.Writer, name string, metrics []Metric) {
+	for _, m := range metrics {
+		w.WriteString(name)
+		if len(m.Labels) > 0 {
+			w.WriteString("{")
+			for i, l := range m.Labels {
+				if i > 0 {
+					w.WriteString(",")
+				}
+				fmt.Fprintf(w, `%s="%s"`, l.Name, EscapeLabelValue(l.Value))
+			}
+			w.WriteString("}")
+		}
+		w.WriteString(" ")
+		w.WriteString(FormatValue(m.Value))
+		w.WriteString("\n")
+	}
+}
+
+// flush flushes w, reporting write errors
+func flush(w *bufio.Writer) error {
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// labelEscaper escapes label values as both formats require
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// EscapeLabelValue escapes backslashes, double quotes and line feeds
+func EscapeLabelValue(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// escapeHelp escapes HELP text. The text format escapes backslashes and
+// line feeds, OpenMetrics double quotes as well.
+func escapeHelp(s string, openMetrics bool) string {
+	if openMetrics {
+		return labelEscaper.Replace(s)
+	}
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+// FormatValue formats a sample value. Integral values, such as unix
+// timestamps, are written without exponent.
+func FormatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		return strconv.FormatInt(int64(v), 10)
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
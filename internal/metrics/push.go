@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultPushJob is the job label of pushed metrics when none is set
+const DefaultPushJob = "sslcheckdomain"
+
+// Pusher sends metric families to a Prometheus Pushgateway. Every push
+// replaces the metrics of its group, identified by the job and the grouping
+// labels, so series of domains that are no longer checked disappear.
+type Pusher struct {
+	// URL is the base URL of the Pushgateway, e.g. http://pushgateway:9091.
+	// User info in the URL is sent as basic auth.
+	URL string
+	// Job is the job label, defaults to DefaultPushJob
+	Job string
+	// Grouping holds additional grouping labels, e.g. instance or env
+	Grouping map[string]string
+	// Client is the HTTP client, defaults to one with a 30 second timeout
+	Client *http.Client
+}
+
+// Validate checks the URL and the grouping labels
+func (p *Pusher) Validate() error {
+	_, err := p.endpoint()
+	return err
+}
+
+// Redacted returns the URL with the password of its user info masked
+func (p *Pusher) Redacted() string {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return p.URL
+	}
+	return u.Redacted()
+}
+
+// Push replaces the metrics of the group with families
+func (p *Pusher) Push(ctx context.Context, families []Family) error {
+	endpoint, err := p.endpoint()
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	if err := WriteText(&body, families); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, &body)
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentTypeText)
+
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send push request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("pushgateway returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// endpoint returns the URL of the group:
+// <url>/metrics/job/<job>/<label>/<value>..., with grouping labels sorted
+func (p *Pusher) endpoint() (string, error) {
+	if p.URL == "" {
+		return "", fmt.Errorf("pushgateway URL is required")
+	}
+	base, err := url.Parse(p.URL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return "", fmt.Errorf("invalid pushgateway URL: %s", p.Redacted())
+	}
+
+	job := p.Job
+	if job == "" {
+		job = DefaultPushJob
+	}
+
+	names := make([]string, 0, len(p.Grouping))
+	for name := range p.Grouping {
+		if name == "" || name == "job" {
+			return "", fmt.Errorf("invalid grouping label: %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var path strings.Builder
+	path.WriteString(strings.TrimSuffix(base.Path, "/"))
+	path.WriteString("/metrics/")
+	path.WriteString(pathSegment("job", job))
+	for _, name := range names {
+		path.WriteString("/")
+		path.WriteString(pathSegment(name, p.Grouping[name]))
+	}
+
+	base.Path = ""
+	base.RawPath = ""
+	return base.String() + path.String(), nil
+}
+
+// pathSegment encodes a label as name/value path segments. Values that are
+// empty or contain a slash use the Pushgateway's base64 encoding.
+func pathSegment(name, value string) string {
+	if value == "" || strings.Contains(value, "/") {
+		encoded := base64.RawURLEncoding.EncodeToString([]byte(value))
+		if encoded == "" {
+			encoded = "="
+		}
+		return name + "@base64/" + encoded
+	}
+	return name + "/" + url.PathEscape(value)
+}
@@ -0,0 +1,263 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// csvColumn is a column of the CSV and TSV exports
+type csvColumn struct {
+	name  string
+	value func(cert models.Certificate) string
+}
+
+// csvColumns are the available columns in their default order. The default
+// output has every column, so it grows with each new column; consumers that
+// need a fixed layout select their columns.
+var csvColumns = []csvColumn{
+	{"domain", func(c models.Certificate) string { return c.Domain }},
+	{"port", func(c models.Certificate) string { return csvPort(c.Port) }},
+	{"status", func(c models.Certificate) string { return string(c.Status) }},
+	{"days_left", func(c models.Certificate) string { return csvDays(c) }},
+	{"expires_at", func(c models.Certificate) string { return csvTime(c.ExpiresAt) }},
+	{"issued_at", func(c models.Certificate) string { return csvTime(c.IssuedAt) }},
+	{"issuer", func(c models.Certificate) string { return c.Issuer }},
+	{"subject", func(c models.Certificate) string { return c.Subject }},
+	{"serial_number", func(c models.Certificate) string { return c.SerialNumber }},
+	{"sans", func(c models.Certificate) string { return strings.Join(c.SANs, " ") }},
+	{"san_count", func(c models.Certificate) string { return strconv.Itoa(len(c.SANs)) }},
+	{"chain", func(c models.Certificate) string { return csvChain(c.Chain) }},
+	{"chain_length", func(c models.Certificate) string { return csvChainLength(c) }},
+	{"chain_expires_at", func(c models.Certificate) string { return csvChainExpiry(c.Chain) }},
+	{"warning_days", func(c models.Certificate) string { return strconv.Itoa(c.WarningDays) }},
+	{"critical_days", func(c models.Certificate) string { return strconv.Itoa(c.CriticalDays) }},
+	{"tags", func(c models.Certificate) string { return strings.Join(c.Tags, " ") }},
+	{"provider", func(c models.Certificate) string { return c.Provider }},
+	{"instance", func(c models.Certificate) string { return c.Instance }},
+	{"tls_secret", func(c models.Certificate) string { return c.TLSSecret }},
+	{"cert_file", func(c models.Certificate) string { return c.CertFile }},
+	{"wildcard", func(c models.Certificate) string { return c.Wildcard }},
+	{"notes", func(c models.Certificate) string { return strings.Join(c.Notes, "; ") }},
+	{"error", func(c models.Certificate) string { return csvError(c.Error) }},
+	{"check_duration_ms", func(c models.Certificate) string { return strconv.FormatInt(c.Duration.Milliseconds(), 10) }},
+}
+
+// CSVColumns returns the names of the columns available to CSV and TSV
+// exports in their default order
+func CSVColumns() []string {
+	names := make([]string, 0, len(csvColumns))
+	for _, col := range csvColumns {
+		names = append(names, col.name)
+	}
+	return names
+}
+
+// ValidateColumns checks that the columns are available to CSV and TSV
+// exports
+func ValidateColumns(columns []string) error {
+	_, err := selectColumns(columns)
+	return err
+}
+
+// CSVFormatter writes the report as delimiter separated values, one row per
+// certificate. CSV output is quoted as described in RFC 4180.
+type CSVFormatter struct {
+	w       *csv.Writer
+	columns []csvColumn
+}
+
+// NewCSVFormatter creates a comma separated values formatter writing the
+// given columns, or all columns if none are given. Cells that spreadsheets
+// would evaluate as formulas are escaped, see csvCell.
+func NewCSVFormatter(w io.Writer, columns []string) (*CSVFormatter, error) {
+	f, err := newDelimitedFormatter(w, ',', columns)
+	if err != nil {
+		return nil, err
+	}
+	// RFC 4180 records end with CRLF
+	f.w.UseCRLF = true
+	return f, nil
+}
+
+// NewTSVFormatter creates a tab separated values formatter writing the given
+// columns, or all columns if none are given
+func NewTSVFormatter(w io.Writer, columns []string) (*CSVFormatter, error) {
+	return newDelimitedFormatter(w, '\t', columns)
+}
+
+// newDelimitedFormatter creates a formatter for the delimiter and columns
+func newDelimitedFormatter(w io.Writer, comma rune, columns []string) (*CSVFormatter, error) {
+	selected, err := selectColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &CSVFormatter{w: cw, columns: selected}, nil
+}
+
+// selectColumns looks up the named columns, all columns if none are named
+func selectColumns(names []string) ([]csvColumn, error) {
+	if len(names) == 0 {
+		return csvColumns, nil
+	}
+
+	selected := make([]csvColumn, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		found := false
+		for _, col := range csvColumns {
+			if col.name == name {
+				selected = append(selected, col)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown column: %s (valid: %s)", name, strings.Join(CSVColumns(), ", "))
+		}
+	}
+	if len(selected) == 0 {
+		return csvColumns, nil
+	}
+	return selected, nil
+}
+
+// Format writes the header and one row per certificate
+func (f *CSVFormatter) Format(report *models.CertificateReport) error {
+	if err := f.Begin(); err != nil {
+		return err
+	}
+	for _, cert := range report.Certificates {
+		if err := f.WriteCertificate(cert); err != nil {
+			return err
+		}
+	}
+	return f.End()
+}
+
+// Begin writes the header row
+func (f *CSVFormatter) Begin() error {
+	header := make([]string, len(f.columns))
+	for i, col := range f.columns {
+		header[i] = col.name
+	}
+	return f.write(header)
+}
+
+// WriteCertificate writes the row of a certificate
+func (f *CSVFormatter) WriteCertificate(cert models.Certificate) error {
+	row := make([]string, len(f.columns))
+	for i, col := range f.columns {
+		row[i] = csvCell(col.value(cert))
+	}
+	return f.write(row)
+}
+
+// End flushes the remaining output
+func (f *CSVFormatter) End() error {
+	f.w.Flush()
+	if err := f.w.Error(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// write writes a record and flushes it, so streamed rows show up as soon
+// as the certificate is checked
+func (f *CSVFormatter) write(record []string) error {
+	if err := f.w.Write(record); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	f.w.Flush()
+	if err := f.w.Error(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// csvCell escapes a cell that spreadsheets would evaluate as a formula by
+// prefixing it with a single quote. Issuers, subjects, SANs and errors come
+// from remote servers and must not run in the sheet. Numbers such as
+// negative day counts are left as they are.
+func csvCell(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// csvPort returns the port, 443 when unset
+func csvPort(port int) string {
+	if port == 0 {
+		port = 443
+	}
+	return strconv.Itoa(port)
+}
+
+// csvDays returns the days left, empty for failed checks
+func csvDays(cert models.Certificate) string {
+	if cert.Error != nil || cert.Status == models.StatusError {
+		return ""
+	}
+	return strconv.Itoa(cert.DaysLeft)
+}
+
+// csvTime formats a timestamp in UTC, empty when unset
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// csvChain lists the subjects of the chain certificates, starting with the
+// issuer of the leaf
+func csvChain(chain []models.ChainCert) string {
+	subjects := make([]string, 0, len(chain))
+	for _, c := range chain {
+		subjects = append(subjects, c.Subject)
+	}
+	return strings.Join(subjects, " > ")
+}
+
+// csvChainLength counts the leaf and the chain certificates, empty for
+// failed checks
+func csvChainLength(cert models.Certificate) string {
+	if cert.SerialNumber == "" {
+		return ""
+	}
+	return strconv.Itoa(len(cert.Chain) + 1)
+}
+
+// csvChainExpiry returns the earliest expiry among the chain certificates
+func csvChainExpiry(chain []models.ChainCert) string {
+	var earliest time.Time
+	for _, c := range chain {
+		if earliest.IsZero() || c.ExpiresAt.Before(earliest) {
+			earliest = c.ExpiresAt
+		}
+	}
+	return csvTime(earliest)
+}
+
+// csvError returns the error message, empty on success
+func csvError(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"sslcheckdomain/pkg/models"
+)
+
+func TestCSVEscapesFormulas(t *testing.T) {
+	var out strings.Builder
+	f, err := NewCSVFormatter(&out, []string{"domain", "days_left", "issuer", "subject", "error"})
+	if err != nil {
+		t.Fatalf("NewCSVFormatter() error = %v", err)
+	}
+
+	report := &models.CertificateReport{Certificates: []models.Certificate{
+		{Domain: "evil.test", Status: models.StatusExpired, DaysLeft: -3, Issuer: `=HYPERLINK("http://evil.test")`, Subject: "@SUM(A1)"},
+		{Domain: "down.test", Status: models.StatusError, Error: errors.New("-2+3 refused")},
+	}}
+	if err := f.Format(report); err != nil {
+		t.Fatalf("Format() error = %v", err)
+	}
+
+	want := "domain,days_left,issuer,subject,error\r\n" +
+		`evil.test,-3,"'=HYPERLINK(""http://evil.test"")",'@SUM(A1),` + "\r\n" +
+		"down.test,,,,'-2+3 refused\r\n"
+	if out.String() != want {
+		t.Errorf("Format() =\n%q\nwant\n%q", out.String(), want)
+	}
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"sslcheckdomain/pkg/models"
+)
+
+//go:embed templates/report.html
+var htmlReportTemplate string
+
+// htmlStatusOrder ranks statuses for sorting, most severe first
+var htmlStatusOrder = map[models.CertificateStatus]int{
+	models.StatusExpired:  0,
+	models.StatusCritical: 1,
+	models.StatusError:    2,
+	models.StatusWarning:  3,
+	models.StatusOK:       4,
+}
+
+// HTMLFormatter renders the report as a single static HTML page with a
+// summary, a sortable and filterable table and a detail panel per
+// certificate. Styles and scripts are inlined, so the file can be published
+// as is.
+type HTMLFormatter struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+// htmlReport is the data passed to the report template
+type htmlReport struct {
+	Generated    time.Time
+	Summary      models.ReportSummary
+	Total        int
+	Certificates []htmlCertificate
+}
+
+// htmlCertificate is a certificate with the values the template displays
+type htmlCertificate struct {
+	models.Certificate
+	Name      string
+	Rank      int
+	Label     string
+	ErrorText string
+}
+
+// NewHTMLFormatter creates a new HTML report formatter
+func NewHTMLFormatter(w io.Writer) *HTMLFormatter {
+	funcs := template.FuncMap{
+		"date": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.UTC().Format("2006-01-02 15:04 UTC")
+		},
+		"unix": func(t time.Time) int64 {
+			if t.IsZero() {
+				return 0
+			}
+			return t.Unix()
+		},
+		"join": strings.Join,
+		"ms": func(d time.Duration) int64 {
+			return d.Milliseconds()
+		},
+	}
+
+	return &HTMLFormatter{
+		w:    w,
+		tmpl: template.Must(template.New("report").Funcs(funcs).Parse(htmlReportTemplate)),
+	}
+}
+
+// Format renders the report
+func (f *HTMLFormatter) Format(report *models.CertificateReport) error {
+	data := htmlReport{
+		Generated:    report.Timestamp,
+		Summary:      report.Summary,
+		Total:        report.TotalDomains,
+		Certificates: make([]htmlCertificate, 0, len(report.Certificates)),
+	}
+	if data.Generated.IsZero() {
+		data.Generated = time.Now()
+	}
+
+	for _, cert := range report.Certificates {
+		c := htmlCertificate{
+			Certificate: cert,
+			Name:        cert.Domain,
+			Rank:        htmlStatusOrder[cert.Status],
+			Label:       f.label(cert.Status),
+		}
+		if cert.Port != 0 && cert.Port != 443 {
+			c.Name = fmt.Sprintf("%s:%d", cert.Domain, cert.Port)
+		}
+		if cert.Error != nil {
+			c.ErrorText = cert.Error.Error()
+		}
+		data.Certificates = append(data.Certificates, c)
+	}
+
+	if err := f.tmpl.Execute(f.w, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+// label returns the status badge text, matching the table output
+func (f *HTMLFormatter) label(status models.CertificateStatus) string {
+	switch status {
+	case models.StatusExpired:
+		return "✗ EXPIRED"
+	case models.StatusCritical:
+		return "‼ CRIT"
+	case models.StatusWarning:
+		return "⚠ WARN"
+	case models.StatusOK:
+		return "✓ OK"
+	case models.StatusError:
+		return "✗ ERROR"
+	default:
+		return "? UNKNOWN"
+	}
+}
@@ -0,0 +1,186 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// junitDefaultSuite names the test suite of certificates without a provider
+// instance, e.g. targets from --test or --from-file
+const junitDefaultSuite = "sslcheckdomain"
+
+// JUnitFormatter formats the report as JUnit XML for CI systems. Every
+// certificate is a test case, grouped into one suite per provider instance.
+// Expired, critical and warning certificates are failures and failed checks
+// are errors.
+type JUnitFormatter struct {
+	w io.Writer
+}
+
+// NewJUnitFormatter creates a new JUnit XML formatter
+func NewJUnitFormatter(w io.Writer) *JUnitFormatter {
+	return &JUnitFormatter{w: w}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut *junitText    `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",cdata"`
+}
+
+type junitText struct {
+	Text string `xml:",cdata"`
+}
+
+// Format writes the report as JUnit XML
+func (f *JUnitFormatter) Format(report *models.CertificateReport) error {
+	suites := make(map[string]*junitTestSuite)
+	durations := make(map[string]time.Duration)
+	timestamp := report.Timestamp.Format("2006-01-02T15:04:05")
+
+	for _, cert := range report.Certificates {
+		name := cert.Instance
+		if name == "" {
+			name = junitDefaultSuite
+		}
+		suite, ok := suites[name]
+		if !ok {
+			suite = &junitTestSuite{Name: name, Timestamp: timestamp}
+			suites[name] = suite
+		}
+
+		tc := f.testCase(name, cert)
+		suite.Tests++
+		if tc.Failure != nil {
+			suite.Failures++
+		}
+		if tc.Error != nil {
+			suite.Errors++
+		}
+		durations[name] += cert.Duration
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	names := make([]string, 0, len(suites))
+	for name := range suites {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	doc := junitTestSuites{Name: junitDefaultSuite}
+	var total time.Duration
+	for _, name := range names {
+		suite := suites[name]
+		// Checks finish in any order, so sort for stable CI history
+		sort.SliceStable(suite.Cases, func(i, j int) bool {
+			return suite.Cases[i].Name < suite.Cases[j].Name
+		})
+		suite.Time = junitSeconds(durations[name])
+		total += durations[name]
+
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		doc.Suites = append(doc.Suites, *suite)
+	}
+	doc.Time = junitSeconds(total)
+
+	output, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit XML: %w", err)
+	}
+
+	_, err = fmt.Fprintf(f.w, "%s%s\n", xml.Header, output)
+	return err
+}
+
+// testCase converts a certificate to a test case
+func (f *JUnitFormatter) testCase(suite string, cert models.Certificate) junitTestCase {
+	name := cert.Domain
+	if cert.Port != 0 && cert.Port != 443 {
+		name = fmt.Sprintf("%s:%d", cert.Domain, cert.Port)
+	}
+
+	tc := junitTestCase{
+		Name:      name,
+		ClassName: suite,
+		Time:      junitSeconds(cert.Duration),
+	}
+	if len(cert.Notes) > 0 {
+		tc.SystemOut = &junitText{Text: strings.Join(cert.Notes, "\n")}
+	}
+
+	if cert.Error != nil || cert.Status == models.StatusError {
+		message := "certificate check failed"
+		if cert.Error != nil {
+			message = cert.Error.Error()
+		}
+		tc.Error = &junitProblem{
+			Message: message,
+			Type:    string(models.StatusError),
+			Text:    message,
+		}
+		return tc
+	}
+
+	expires := cert.ExpiresAt.Format("2006-01-02")
+	var message string
+	switch cert.Status {
+	case models.StatusExpired:
+		message = fmt.Sprintf("certificate expired %d days ago (%s)", -cert.DaysLeft, expires)
+	case models.StatusCritical:
+		message = fmt.Sprintf("certificate expires in %d days (%s), critical threshold is %d days", cert.DaysLeft, expires, cert.CriticalDays)
+	case models.StatusWarning:
+		message = fmt.Sprintf("certificate expires in %d days (%s), warning threshold is %d days", cert.DaysLeft, expires, cert.WarningDays)
+	default:
+		return tc
+	}
+
+	tc.Failure = &junitProblem{
+		Message: message,
+		Type:    string(cert.Status),
+		Text: fmt.Sprintf("Domain: %s\nExpires: %s\nIssuer: %s\nSubject: %s\nSerial: %s",
+			cert.Domain, cert.ExpiresAt.Format(time.RFC3339), cert.Issuer, cert.Subject, cert.SerialNumber),
+	}
+	return tc
+}
+
+// junitSeconds formats a duration as seconds with millisecond precision
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
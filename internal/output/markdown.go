@@ -0,0 +1,195 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// markdownEscaper escapes the characters with a meaning in GitHub-flavored
+// Markdown table cells
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+	"|", `\|`,
+	"\r", " ",
+	"\n", " ",
+)
+
+// MarkdownFormatter formats the report as GitHub-flavored Markdown, for PR
+// comments and wiki pages: a summary line, a status table and a collapsible
+// details section per certificate that needs attention or failed.
+type MarkdownFormatter struct {
+	w io.Writer
+}
+
+// NewMarkdownFormatter creates a new Markdown formatter
+func NewMarkdownFormatter(w io.Writer) *MarkdownFormatter {
+	return &MarkdownFormatter{w: w}
+}
+
+// Format writes the Markdown report
+func (f *MarkdownFormatter) Format(report *models.CertificateReport) error {
+	w := bufio.NewWriter(f.w)
+
+	fmt.Fprintln(w, "## SSL Certificate Expiration Report")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s · %d certificates checked %s\n\n",
+		f.summary(report.Summary),
+		report.TotalDomains,
+		report.Timestamp.UTC().Format("2006-01-02 15:04 UTC"),
+	)
+
+	if len(report.Certificates) > 0 {
+		fmt.Fprintln(w, "| Domain | Status | Days Left | Expires | Issuer |")
+		fmt.Fprintln(w, "|--------|--------|----------:|---------|--------|")
+		for _, cert := range report.Certificates {
+			if cert.Error != nil {
+				fmt.Fprintf(w, "| %s | %s | N/A | N/A | %s |\n",
+					f.escape(f.name(cert)), f.status(cert.Status), f.escape(cert.Error.Error()))
+				continue
+			}
+			fmt.Fprintf(w, "| %s | %s | %d | %s | %s |\n",
+				f.escape(f.name(cert)),
+				f.status(cert.Status),
+				cert.DaysLeft,
+				cert.ExpiresAt.Format("2006-01-02"),
+				f.escape(cert.Issuer),
+			)
+		}
+	}
+
+	for _, cert := range report.Certificates {
+		if cert.IsHealthy() {
+			continue
+		}
+		fmt.Fprintln(w)
+		f.details(w, cert)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// summary returns the status counts, omitting statuses without certificates
+func (f *MarkdownFormatter) summary(s models.ReportSummary) string {
+	counts := []struct {
+		status models.CertificateStatus
+		count  int
+	}{
+		{models.StatusExpired, s.Expired},
+		{models.StatusCritical, s.Critical},
+		{models.StatusWarning, s.Warning},
+		{models.StatusError, s.Error},
+		{models.StatusOK, s.OK},
+	}
+
+	parts := make([]string, 0, len(counts))
+	for _, c := range counts {
+		if c.count > 0 {
+			parts = append(parts, fmt.Sprintf("%s **%d** %s", f.emoji(c.status), c.count, c.status))
+		}
+	}
+	if len(parts) == 0 {
+		return "No certificates"
+	}
+	return strings.Join(parts, " · ")
+}
+
+// details writes the collapsible details of a certificate
+func (f *MarkdownFormatter) details(w io.Writer, cert models.Certificate) {
+	var headline string
+	switch {
+	case cert.Error != nil:
+		headline = "check failed"
+	case cert.DaysLeft < 0:
+		headline = fmt.Sprintf("expired %d days ago", -cert.DaysLeft)
+	default:
+		headline = fmt.Sprintf("expires in %d days", cert.DaysLeft)
+	}
+
+	// The summary is raw HTML; the blank line after it enables Markdown
+	// inside the block
+	fmt.Fprintf(w, "<details>\n<summary>%s <b>%s</b>: %s</summary>\n\n",
+		f.emoji(cert.Status), html.EscapeString(f.name(cert)), headline)
+
+	if cert.Error != nil {
+		fmt.Fprintf(w, "```\n%s\n```\n\n", strings.ReplaceAll(cert.Error.Error(), "```", "'''"))
+	} else {
+		fmt.Fprintf(w, "- **Expires:** %s\n", cert.ExpiresAt.UTC().Format("2006-01-02 15:04 UTC"))
+		fmt.Fprintf(w, "- **Issuer:** %s\n", f.escape(cert.Issuer))
+		fmt.Fprintf(w, "- **Subject:** %s\n", f.escape(cert.Subject))
+		fmt.Fprintf(w, "- **Serial:** `%s`\n", cert.SerialNumber)
+		if len(cert.SANs) > 0 {
+			fmt.Fprintf(w, "- **Alternative names:** %s\n", f.escape(strings.Join(cert.SANs, ", ")))
+		}
+		if len(cert.Chain) > 0 {
+			subjects := make([]string, 0, len(cert.Chain))
+			for _, c := range cert.Chain {
+				subjects = append(subjects, f.escape(c.Subject))
+			}
+			fmt.Fprintf(w, "- **Chain:** %s\n", strings.Join(subjects, " → "))
+		}
+	}
+	fmt.Fprintf(w, "- **Thresholds:** warning %d days, critical %d days\n", cert.WarningDays, cert.CriticalDays)
+	if len(cert.Tags) > 0 {
+		fmt.Fprintf(w, "- **Tags:** %s\n", f.escape(strings.Join(cert.Tags, ", ")))
+	}
+	if cert.Instance != "" {
+		fmt.Fprintf(w, "- **Source:** %s\n", f.escape(cert.Instance))
+	}
+	for _, note := range cert.Notes {
+		fmt.Fprintf(w, "- ⚑ %s\n", f.escape(note))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "</details>")
+}
+
+// name returns the domain, with the port when it is not 443
+func (f *MarkdownFormatter) name(cert models.Certificate) string {
+	if cert.Port != 0 && cert.Port != 443 {
+		return fmt.Sprintf("%s:%d", cert.Domain, cert.Port)
+	}
+	return cert.Domain
+}
+
+// status returns the status with its emoji
+func (f *MarkdownFormatter) status(status models.CertificateStatus) string {
+	return fmt.Sprintf("%s %s", f.emoji(status), strings.ToUpper(string(status)))
+}
+
+// emoji returns the colored marker of a status
+func (f *MarkdownFormatter) emoji(status models.CertificateStatus) string {
+	switch status {
+	case models.StatusExpired:
+		return "🔴"
+	case models.StatusCritical:
+		return "🟠"
+	case models.StatusWarning:
+		return "🟡"
+	case models.StatusOK:
+		return "🟢"
+	case models.StatusError:
+		return "🟣"
+	default:
+		return "⚪"
+	}
+}
+
+// escape escapes text for use in Markdown
+func (f *MarkdownFormatter) escape(s string) string {
+	return markdownEscaper.Replace(s)
+}
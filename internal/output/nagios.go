@@ -0,0 +1,162 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// Nagios plugin states and their exit codes
+const (
+	nagiosOK       = 0
+	nagiosWarning  = 1
+	nagiosCritical = 2
+	nagiosUnknown  = 3
+)
+
+// nagiosStates are the state names indexed by exit code
+var nagiosStates = []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}
+
+// NagiosFormatter formats the report in the Nagios/Icinga plugin format: a
+// status line with performance data followed by one line per certificate.
+// The thresholds are used for certificates that do not carry their own.
+type NagiosFormatter struct {
+	w        io.Writer
+	warning  int
+	critical int
+}
+
+// NewNagiosFormatter creates a new Nagios plugin formatter
+func NewNagiosFormatter(w io.Writer, warning, critical int) *NagiosFormatter {
+	return &NagiosFormatter{
+		w:        w,
+		warning:  warning,
+		critical: critical,
+	}
+}
+
+// Format writes the plugin output
+func (f *NagiosFormatter) Format(report *models.CertificateReport) error {
+	counts := make([]int, len(nagiosStates))
+	perfdata := make([]string, 0, len(report.Certificates))
+	for _, cert := range report.Certificates {
+		counts[f.state(cert)]++
+		perfdata = append(perfdata, f.perfdata(cert))
+	}
+
+	state := f.ExitCode(report)
+	summary := "no certificates to check"
+	if len(report.Certificates) > 0 {
+		parts := make([]string, 0, len(nagiosStates))
+		for _, s := range []int{nagiosCritical, nagiosUnknown, nagiosWarning, nagiosOK} {
+			if counts[s] > 0 {
+				parts = append(parts, fmt.Sprintf("%d %s", counts[s], strings.ToLower(nagiosStates[s])))
+			}
+		}
+		summary = fmt.Sprintf("%s of %d certificates", strings.Join(parts, ", "), len(report.Certificates))
+	}
+
+	line := fmt.Sprintf("SSL %s - %s", nagiosStates[state], summary)
+	if len(perfdata) > 0 {
+		line += " | " + strings.Join(perfdata, " ")
+	}
+	if _, err := fmt.Fprintln(f.w, line); err != nil {
+		return err
+	}
+
+	// Long output, most severe first
+	for _, s := range []int{nagiosCritical, nagiosUnknown, nagiosWarning, nagiosOK} {
+		for _, cert := range report.Certificates {
+			if f.state(cert) == s {
+				if _, err := fmt.Fprintf(f.w, "[%s] %s\n", nagiosStates[s], f.describe(cert)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExitCode returns the plugin exit code: CRITICAL if any certificate is
+// critical, then UNKNOWN if any check failed, then WARNING, otherwise OK
+func (f *NagiosFormatter) ExitCode(report *models.CertificateReport) int {
+	worst := nagiosOK
+	for _, cert := range report.Certificates {
+		switch state := f.state(cert); {
+		case state == nagiosCritical:
+			return nagiosCritical
+		case state == nagiosUnknown:
+			worst = nagiosUnknown
+		case state == nagiosWarning && worst == nagiosOK:
+			worst = nagiosWarning
+		}
+	}
+	return worst
+}
+
+// state maps a certificate to its plugin state
+func (f *NagiosFormatter) state(cert models.Certificate) int {
+	switch {
+	case cert.Status == models.StatusError || cert.Error != nil:
+		return nagiosUnknown
+	case cert.Status == models.StatusExpired || cert.Status == models.StatusCritical:
+		return nagiosCritical
+	case cert.Status == models.StatusWarning:
+		return nagiosWarning
+	default:
+		return nagiosOK
+	}
+}
+
+// label returns the perfdata label and long output name of a certificate
+func (f *NagiosFormatter) label(cert models.Certificate) string {
+	if cert.Port != 0 && cert.Port != 443 {
+		return fmt.Sprintf("%s:%d", cert.Domain, cert.Port)
+	}
+	return cert.Domain
+}
+
+// perfdata returns the days_left performance data of a certificate. The
+// thresholds use the plugin range syntax "N:", alerting below N days, so a
+// threshold of N days, which alerts at N days left, is written as N+1.
+func (f *NagiosFormatter) perfdata(cert models.Certificate) string {
+	warning, critical := cert.WarningDays, cert.CriticalDays
+	if warning == 0 {
+		warning = f.warning
+	}
+	if critical == 0 {
+		critical = f.critical
+	}
+
+	value := "U"
+	if cert.Error == nil && cert.Status != models.StatusError {
+		value = fmt.Sprintf("%d", cert.DaysLeft)
+	}
+
+	// Single quotes in labels are escaped by doubling them
+	label := strings.ReplaceAll(f.label(cert), "'", "''")
+	return fmt.Sprintf("'%s'=%s;%d:;%d:", label, value, warning+1, critical+1)
+}
+
+// describe returns the long output line of a certificate
+func (f *NagiosFormatter) describe(cert models.Certificate) string {
+	name := f.label(cert)
+	if cert.Error != nil {
+		return fmt.Sprintf("%s: %v", name, cert.Error)
+	}
+
+	expires := cert.ExpiresAt.Format("2006-01-02")
+	var text string
+	if cert.DaysLeft < 0 {
+		text = fmt.Sprintf("%s: expired %d days ago (%s)", name, -cert.DaysLeft, expires)
+	} else {
+		text = fmt.Sprintf("%s: %d days left (expires %s, issuer %s)", name, cert.DaysLeft, expires, cert.Issuer)
+	}
+	for _, note := range cert.Notes {
+		text += "; " + note
+	}
+	return text
+}
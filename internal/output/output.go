@@ -2,6 +2,9 @@ package output
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"sslcheckdomain/pkg/models"
 )
@@ -11,15 +14,88 @@ type Formatter interface {
 	Format(report *models.CertificateReport) error
 }
 
+// StreamFormatter is implemented by formatters that can write certificates
+// one by one as they are checked, for reports too large to hold in memory.
+// Begin is called before the first certificate and End after the last.
+type StreamFormatter interface {
+	Formatter
+	Begin() error
+	WriteCertificate(cert models.Certificate) error
+	End() error
+}
+
+// ExitCoder is implemented by formatters that define their own exit code
+// semantics, such as monitoring plugin formats
+type ExitCoder interface {
+	ExitCode(report *models.CertificateReport) int
+}
+
+// Options holds the settings formatters may need besides the report
+type Options struct {
+	// Warning is the warning threshold in days
+	Warning int
+	// Critical is the critical threshold in days
+	Critical int
+	// Columns selects the columns of tabular exports, defaults to all
+	Columns []string
+	// Template names a built-in template of the template format
+	Template string
+	// TemplateFile is the template file of the template format, used
+	// instead of a built-in template
+	TemplateFile string
+	// Writer receives the output of formatters that support it, defaults
+	// to stdout
+	Writer io.Writer
+}
+
+// writer returns the configured output writer
+func (o Options) writer() io.Writer {
+	if o.Writer == nil {
+		return os.Stdout
+	}
+	return o.Writer
+}
+
+// Formats returns the supported output formats
+func Formats() []string {
+	return []string{"table", "json", "prometheus", "openmetrics", "nagios", "junit", "csv", "tsv", "html", "markdown", "template"}
+}
+
+// ValidateFormat checks that format is a supported output format
+func ValidateFormat(format string) error {
+	for _, f := range Formats() {
+		if f == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format: %s (valid: %s)", format, strings.Join(Formats(), ", "))
+}
+
 // GetFormatter returns the appropriate formatter based on format string
-func GetFormatter(format string) (Formatter, error) {
+func GetFormatter(format string, opts Options) (Formatter, error) {
 	switch format {
 	case "table":
 		return NewTableFormatter(), nil
 	case "json":
 		return NewJSONFormatter(), nil
 	case "prometheus":
-		return NewPrometheusFormatter(), nil
+		return NewPrometheusFormatter(opts.writer()), nil
+	case "openmetrics":
+		return NewOpenMetricsFormatter(opts.writer()), nil
+	case "nagios":
+		return NewNagiosFormatter(opts.writer(), opts.Warning, opts.Critical), nil
+	case "junit":
+		return NewJUnitFormatter(opts.writer()), nil
+	case "csv":
+		return NewCSVFormatter(opts.writer(), opts.Columns)
+	case "tsv":
+		return NewTSVFormatter(opts.writer(), opts.Columns)
+	case "html":
+		return NewHTMLFormatter(opts.writer()), nil
+	case "markdown":
+		return NewMarkdownFormatter(opts.writer()), nil
+	case "template":
+		return NewTemplateFormatter(opts.writer(), opts.Template, opts.TemplateFile)
 	default:
 		return nil, fmt.Errorf("unsupported output format: %s", format)
 	}
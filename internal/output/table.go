@@ -51,7 +51,7 @@ func (f *TableFormatter) Format(report *models.CertificateReport) error {
 		}
 
 		t.AppendRow(table.Row{
-			f.formatDomain(cert.Domain),
+			f.formatTarget(cert),
 			status,
 			daysLeft,
 			expires,
@@ -63,11 +63,13 @@ func (f *TableFormatter) Format(report *models.CertificateReport) error {
 	t.AppendSeparator()
 
 	// Add summary with colors
-	summary := fmt.Sprintf("%s %d  │  %s %d  │  %s %d  │  %s %d  │  %s %d",
+	summary := fmt.Sprintf("%s %d  │  %s %d  │  %s %d  │  %s %d  │  %s %d  │  %s %d",
 		text.Colors{text.FgHiCyan}.Sprint("Total:"),
 		report.TotalDomains,
 		text.Colors{text.FgHiRed}.Sprint("Expired:"),
 		report.Summary.Expired,
+		text.Colors{text.FgRed}.Sprint("Critical:"),
+		report.Summary.Critical,
 		text.Colors{text.FgHiYellow}.Sprint("Warning:"),
 		report.Summary.Warning,
 		text.Colors{text.FgHiGreen}.Sprint("OK:"),
@@ -92,6 +94,8 @@ func (f *TableFormatter) formatStatus(status models.CertificateStatus) string {
 	switch status {
 	case models.StatusExpired:
 		return text.Colors{text.FgHiRed, text.Bold}.Sprint("✗ EXPIRED")
+	case models.StatusCritical:
+		return text.Colors{text.FgRed, text.Bold}.Sprint("‼ CRIT")
 	case models.StatusWarning:
 		return text.Colors{text.FgHiYellow, text.Bold}.Sprint("⚠ WARN")
 	case models.StatusOK:
@@ -108,12 +112,26 @@ func (f *TableFormatter) formatDomain(domain string) string {
 	return text.Colors{text.FgHiWhite}.Sprint(domain)
 }
 
+// formatTarget formats the domain together with its linked secret and notes
+func (f *TableFormatter) formatTarget(cert models.Certificate) string {
+	target := f.formatDomain(cert.Domain)
+	if cert.TLSSecret != "" && cert.TLSSecret != cert.Domain {
+		target += text.Colors{text.Faint}.Sprintf(" (secret %s)", cert.TLSSecret)
+	}
+	for _, note := range cert.Notes {
+		target += "\n" + text.Colors{text.FgHiYellow}.Sprint("⚑ "+note)
+	}
+	return target
+}
+
 // formatDaysLeft formats days left with color coding
 func (f *TableFormatter) formatDaysLeft(days int, status models.CertificateStatus) string {
 	daysStr := fmt.Sprintf("%d", days)
 	switch status {
 	case models.StatusExpired:
 		return text.Colors{text.FgHiRed}.Sprint(daysStr)
+	case models.StatusCritical:
+		return text.Colors{text.FgRed}.Sprint(daysStr)
 	case models.StatusWarning:
 		return text.Colors{text.FgHiYellow}.Sprint(daysStr)
 	case models.StatusOK:
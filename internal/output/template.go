@@ -0,0 +1,227 @@
+package output
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/text"
+	"golang.org/x/net/publicsuffix"
+	"sslcheckdomain/pkg/models"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+// TemplateGroup is a named group of certificates, as returned by the
+// groupByIssuer and groupByDomain template functions
+type TemplateGroup struct {
+	Name         string
+	Certificates []models.Certificate
+}
+
+// TemplateFormatter renders the report through a user-defined or built-in
+// text/template. The template receives the *models.CertificateReport.
+type TemplateFormatter struct {
+	w    io.Writer
+	tmpl *template.Template
+}
+
+// TemplateNames returns the names of the built-in templates
+func TemplateNames() []string {
+	entries, _ := builtinTemplates.ReadDir("templates")
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".tmpl"); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// NewTemplateFormatter creates a formatter for the template file, or for the
+// built-in template of the given name if no file is given
+func NewTemplateFormatter(w io.Writer, name, file string) (*TemplateFormatter, error) {
+	var source string
+	switch {
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		name, source = filepath.Base(file), string(data)
+	case name != "":
+		data, err := builtinTemplates.ReadFile(path.Join("templates", name+".tmpl"))
+		if err != nil {
+			return nil, fmt.Errorf("unknown template: %s (built-in: %s)", name, strings.Join(TemplateNames(), ", "))
+		}
+		source = string(data)
+	default:
+		return nil, fmt.Errorf("template output needs a template file or a built-in template (%s)", strings.Join(TemplateNames(), ", "))
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs()).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	return &TemplateFormatter{w: w, tmpl: tmpl}, nil
+}
+
+// Format renders the report through the template
+func (f *TemplateFormatter) Format(report *models.CertificateReport) error {
+	if err := f.tmpl.Execute(f.w, report); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
+
+// templateFuncs returns the helper functions available to templates
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Dates
+		"date": func(layout string, t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format(layout)
+		},
+		"isodate": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.Format("2006-01-02")
+		},
+
+		// Colors, as in the table output
+		"colorDays":   templateColorDays,
+		"colorStatus": templateColorStatus,
+
+		// Filtering and grouping
+		"withStatus":       templateWithStatus,
+		"attention":        templateAttention,
+		"groupByIssuer":    templateGroupByIssuer,
+		"groupByDomain":    templateGroupByDomain,
+		"registeredDomain": templateRegisteredDomain,
+
+		// Strings
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+		"pad": func(width int, s string) string {
+			return fmt.Sprintf("%-*s", width, s)
+		},
+		"json": func(v interface{}) (string, error) {
+			data, err := json.Marshal(v)
+			return string(data), err
+		},
+	}
+}
+
+// templateColorDays returns the days left colored by status
+func templateColorDays(cert models.Certificate) string {
+	if cert.Error != nil {
+		return text.Colors{text.Faint}.Sprint("N/A")
+	}
+	return (&TableFormatter{}).formatDaysLeft(cert.DaysLeft, cert.Status)
+}
+
+// templateColorStatus returns the status label colored as in the table
+func templateColorStatus(status models.CertificateStatus) string {
+	return (&TableFormatter{}).formatStatus(status)
+}
+
+// templateWithStatus returns the certificates with one of the statuses
+func templateWithStatus(args ...interface{}) ([]models.Certificate, error) {
+	if len(args) < 2 {
+		return nil, fmt.Errorf("withStatus needs at least one status and the certificates")
+	}
+	certs, ok := args[len(args)-1].([]models.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("withStatus needs the certificates as last argument")
+	}
+
+	statuses := make(map[models.CertificateStatus]bool)
+	for _, arg := range args[:len(args)-1] {
+		statuses[models.CertificateStatus(fmt.Sprint(arg))] = true
+	}
+
+	filtered := make([]models.Certificate, 0)
+	for _, cert := range certs {
+		if statuses[cert.Status] {
+			filtered = append(filtered, cert)
+		}
+	}
+	return filtered, nil
+}
+
+// templateAttention returns the certificates that are expired, critical,
+// warning or failed
+func templateAttention(certs []models.Certificate) []models.Certificate {
+	filtered := make([]models.Certificate, 0)
+	for _, cert := range certs {
+		if !cert.IsHealthy() {
+			filtered = append(filtered, cert)
+		}
+	}
+	return filtered
+}
+
+// templateGroupByIssuer groups the certificates by issuer name
+func templateGroupByIssuer(certs []models.Certificate) []TemplateGroup {
+	return templateGroup(certs, func(cert models.Certificate) string {
+		if cert.Issuer == "" {
+			return "unknown"
+		}
+		return cert.Issuer
+	})
+}
+
+// templateGroupByDomain groups the certificates by registered domain. This
+// is not necessarily the DNS zone: hosts of a delegated zone such as
+// dev.example.com belong to example.com.
+func templateGroupByDomain(certs []models.Certificate) []TemplateGroup {
+	return templateGroup(certs, func(cert models.Certificate) string {
+		return templateRegisteredDomain(cert.Domain)
+	})
+}
+
+// templateRegisteredDomain returns the registered domain of a hostname, e.g.
+// example.co.uk for www.example.co.uk, or the hostname if it has none
+func templateRegisteredDomain(domain string) string {
+	registered, err := publicsuffix.EffectiveTLDPlusOne(strings.TrimSuffix(domain, "."))
+	if err != nil {
+		return domain
+	}
+	return registered
+}
+
+// templateGroup groups certificates by key, keeping their order within a
+// group. Groups are sorted by name.
+func templateGroup(certs []models.Certificate, key func(models.Certificate) string) []TemplateGroup {
+	index := make(map[string]int)
+	groups := make([]TemplateGroup, 0)
+	for _, cert := range certs {
+		name := key(cert)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, TemplateGroup{Name: name})
+		}
+		groups[i].Certificates = append(groups[i].Certificates, cert)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Name < groups[j].Name
+	})
+	return groups
+}
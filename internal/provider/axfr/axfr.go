@@ -0,0 +1,217 @@
+package axfr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+	"sslcheckdomain/internal/provider"
+)
+
+// defaultTimeout bounds a zone transfer
+const defaultTimeout = 30 * time.Second
+
+// Config holds the settings of the axfr config section
+type Config struct {
+	Server        string        `mapstructure:"server"`
+	Zones         []string      `mapstructure:"zones"`
+	TSIGName      string        `mapstructure:"tsig_name"`
+	TSIGSecret    string        `mapstructure:"tsig_secret"`
+	TSIGAlgorithm string        `mapstructure:"tsig_algorithm"`
+	Timeout       time.Duration `mapstructure:"timeout"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "axfr",
+		Description: "DNS zone transfer (AXFR) from an authoritative nameserver",
+		Settings: []provider.Setting{
+			{Key: "server", Env: "SSL_CHECK_AXFR_SERVER", Required: true, Description: "Nameserver address (host or host:port)"},
+			{Key: "zones", Env: "SSL_CHECK_AXFR_ZONES", Required: true, Description: "Zones to transfer (comma separated)"},
+			{Key: "tsig_name", Env: "SSL_CHECK_AXFR_TSIG_NAME", Description: "TSIG key name"},
+			{Key: "tsig_secret", Env: "SSL_CHECK_AXFR_TSIG_SECRET", Description: "Base64 encoded TSIG secret"},
+			{Key: "tsig_algorithm", Description: "TSIG algorithm (default hmac-sha256)"},
+			{Key: "timeout", Description: "Transfer timeout (default 30s)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// Provider implements the DNSProvider interface using zone transfers
+type Provider struct {
+	server  string
+	zones   []string
+	tsig    *tsigKey
+	timeout time.Duration
+}
+
+// tsigKey holds the key used to sign transfer requests
+type tsigKey struct {
+	name      string
+	secret    string
+	algorithm string
+}
+
+// New creates a new AXFR provider
+func New(cfg Config) (*Provider, error) {
+	if cfg.Server == "" {
+		return nil, fmt.Errorf("nameserver address is required")
+	}
+	if len(cfg.Zones) == 0 {
+		return nil, fmt.Errorf("at least one zone is required")
+	}
+
+	server := cfg.Server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+
+	p := &Provider{
+		server:  server,
+		zones:   cfg.Zones,
+		timeout: cfg.Timeout,
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultTimeout
+	}
+
+	if cfg.TSIGName != "" || cfg.TSIGSecret != "" {
+		if cfg.TSIGName == "" || cfg.TSIGSecret == "" {
+			return nil, fmt.Errorf("tsig_name and tsig_secret must be set together")
+		}
+		algorithm := cfg.TSIGAlgorithm
+		if algorithm == "" {
+			algorithm = dns.HmacSHA256
+		}
+		p.tsig = &tsigKey{
+			name:      dns.Fqdn(strings.ToLower(cfg.TSIGName)),
+			secret:    cfg.TSIGSecret,
+			algorithm: dns.Fqdn(strings.ToLower(algorithm)),
+		}
+	}
+
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "axfr"
+}
+
+// GetDomains transfers all configured zones
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	domains := make([]string, 0)
+	for _, zone := range p.zones {
+		zoneDomains, err := p.GetDomainsByZone(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, zoneDomains...)
+	}
+	return domains, nil
+}
+
+// GetDomainsByZone transfers a single zone
+func (p *Provider) GetDomainsByZone(ctx context.Context, zoneName string) ([]string, error) {
+	zoneName = provider.NormalizeName(zoneName)
+
+	records, err := p.transfer(ctx, zoneName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transfer zone %s: %w", zoneName, err)
+	}
+
+	domains := []string{zoneName}
+	return append(domains, provider.HostnamesWithWildcards(zoneName, records)...), nil
+}
+
+// transfer performs the AXFR request and collects the received records.
+// The whole transfer is bounded by the timeout and stops when ctx is done.
+func (p *Provider) transfer(ctx context.Context, zoneName string) ([]provider.Record, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", p.server)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetWriteDeadline(deadline)
+	}
+
+	// Closing the connection unblocks the transfer's reads
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	msg := new(dns.Msg)
+	msg.SetAxfr(dns.Fqdn(zoneName))
+
+	t := &dns.Transfer{
+		Conn:         &dns.Conn{Conn: conn},
+		ReadTimeout:  p.timeout,
+		WriteTimeout: p.timeout,
+	}
+	if p.tsig != nil {
+		t.TsigSecret = map[string]string{p.tsig.name: p.tsig.secret}
+		msg.SetTsig(p.tsig.name, p.tsig.algorithm, 300, time.Now().Unix())
+	}
+
+	envelopes, err := t.In(msg, p.server)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	// stopped reports why ctx is done; a read error then stems from the
+	// closed connection
+	stopped := func() error {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s", p.timeout)
+		}
+		return ctx.Err()
+	}
+
+	records := make([]provider.Record, 0)
+	for {
+		select {
+		case <-ctx.Done():
+			// Let the transfer goroutine deliver its final error and exit
+			go func() {
+				for range envelopes {
+				}
+			}()
+			return nil, stopped()
+		case envelope, ok := <-envelopes:
+			if !ok {
+				return records, nil
+			}
+			if envelope.Error != nil {
+				var netErr net.Error
+				if errors.As(envelope.Error, &netErr) && netErr.Timeout() {
+					return nil, fmt.Errorf("timed out after %s", p.timeout)
+				}
+				if ctx.Err() != nil {
+					return nil, stopped()
+				}
+				return nil, envelope.Error
+			}
+			for _, rr := range envelope.RR {
+				header := rr.Header()
+				records = append(records, provider.Record{
+					Name: header.Name,
+					Type: dns.TypeToString[header.Rrtype],
+				})
+			}
+		}
+	}
+}
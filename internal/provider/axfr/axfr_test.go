@@ -0,0 +1,175 @@
+package axfr
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+const testZone = "example.test."
+
+// testRecords is the content of testZone, starting and ending with its SOA
+func testRecords(t *testing.T) []dns.RR {
+	t.Helper()
+
+	lines := []string{
+		testZone + " 300 IN SOA ns.example.test. hostmaster.example.test. 1 7200 3600 1209600 300",
+		testZone + " 300 IN A 192.0.2.1",
+		"www.example.test. 300 IN A 192.0.2.2",
+		"API.example.test. 300 IN AAAA 2001:db8::1",
+		"mail.example.test. 300 IN MX 10 mx.example.test.",
+		"*.apps.example.test. 300 IN CNAME lb.example.test.",
+		testZone + " 300 IN SOA ns.example.test. hostmaster.example.test. 1 7200 3600 1209600 300",
+	}
+
+	records := make([]dns.RR, 0, len(lines))
+	for _, line := range lines {
+		rr, err := dns.NewRR(line)
+		if err != nil {
+			t.Fatalf("invalid record %q: %v", line, err)
+		}
+		records = append(records, rr)
+	}
+	return records
+}
+
+// startServer runs an in-process TCP nameserver with handler and returns
+// its address
+func startServer(t *testing.T, handler dns.HandlerFunc) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+
+	started := make(chan struct{})
+	server := &dns.Server{
+		Listener:          listener,
+		Handler:           handler,
+		NotifyStartedFunc: func() { close(started) },
+	}
+	go server.ActivateAndServe()
+	t.Cleanup(func() { server.Shutdown() })
+
+	<-started
+	return listener.Addr().String()
+}
+
+// transferHandler answers AXFR requests for testZone with records, sent in
+// two envelopes
+func transferHandler(records []dns.RR) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, r *dns.Msg) {
+		if r.Question[0].Qtype != dns.TypeAXFR || r.Question[0].Name != testZone {
+			m := new(dns.Msg)
+			m.SetRcode(r, dns.RcodeRefused)
+			w.WriteMsg(m)
+			return
+		}
+
+		ch := make(chan *dns.Envelope)
+		tr := new(dns.Transfer)
+		done := make(chan error, 1)
+		go func() { done <- tr.Out(w, r, ch) }()
+
+		half := len(records) / 2
+		ch <- &dns.Envelope{RR: records[:half]}
+		ch <- &dns.Envelope{RR: records[half:]}
+		close(ch)
+		<-done
+		w.Hijack()
+	}
+}
+
+func TestGetDomainsByZone(t *testing.T) {
+	addr := startServer(t, transferHandler(testRecords(t)))
+
+	p, err := New(Config{Server: addr, Zones: []string{"example.test"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	domains, err := p.GetDomainsByZone(context.Background(), "Example.Test.")
+	if err != nil {
+		t.Fatalf("GetDomainsByZone() error = %v", err)
+	}
+
+	want := []string{"example.test", "www.example.test", "api.example.test", "*.apps.example.test"}
+	if !reflect.DeepEqual(domains, want) {
+		t.Errorf("GetDomainsByZone() = %v, want %v", domains, want)
+	}
+}
+
+func TestGetDomainsRefused(t *testing.T) {
+	addr := startServer(t, transferHandler(testRecords(t)))
+
+	p, err := New(Config{Server: addr, Zones: []string{"other.test"}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.GetDomains(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "other.test") {
+		t.Errorf("GetDomains() error = %v, want a transfer error for other.test", err)
+	}
+}
+
+// stallingHandler sends the first SOA and then stops sending until the
+// returned release function is called
+func stallingHandler(t *testing.T) (dns.HandlerFunc, func()) {
+	release := make(chan struct{})
+
+	soa := testRecords(t)[0]
+	handler := func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		m.Answer = []dns.RR{soa}
+		w.WriteMsg(m)
+		<-release
+	}
+	return handler, func() { close(release) }
+}
+
+func TestTransferCancel(t *testing.T) {
+	handler, release := stallingHandler(t)
+	addr := startServer(t, handler)
+	defer release()
+
+	p, err := New(Config{Server: addr, Zones: []string{"example.test"}, Timeout: time.Minute})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err = p.GetDomainsByZone(ctx, "example.test")
+	if err == nil || !strings.Contains(err.Error(), context.Canceled.Error()) {
+		t.Errorf("GetDomainsByZone() error = %v, want %v", err, context.Canceled)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetDomainsByZone() returned after %s, want prompt return on cancel", elapsed)
+	}
+}
+
+func TestTransferTimeout(t *testing.T) {
+	handler, release := stallingHandler(t)
+	addr := startServer(t, handler)
+	defer release()
+
+	p, err := New(Config{Server: addr, Zones: []string{"example.test"}, Timeout: 200 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	_, err = p.GetDomainsByZone(context.Background(), "example.test")
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("GetDomainsByZone() error = %v, want a timeout", err)
+	}
+}
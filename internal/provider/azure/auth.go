@@ -0,0 +1,36 @@
+package azure
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultAuthority is the Microsoft identity platform login URL
+const defaultAuthority = "https://login.microsoftonline.com"
+
+// managementScope requests a token for the Azure Resource Manager API
+const managementScope = "https://management.azure.com/.default"
+
+// newTokenSource creates a token source for a service principal using the
+// client credentials grant
+func newTokenSource(authority, tenantID, clientID, clientSecret string) *rest.TokenSource {
+	if authority == "" {
+		authority = defaultAuthority
+	}
+	tokenURL := strings.TrimSuffix(authority, "/") + "/" + url.PathEscape(tenantID) + "/oauth2/v2.0/token"
+	client := rest.NewClient(tokenURL, nil)
+
+	return rest.NewTokenSource(func(ctx context.Context) (rest.Token, error) {
+		var token rest.Token
+		err := client.PostForm(ctx, "", url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {clientSecret},
+			"scope":         {managementScope},
+		}, &token)
+		return token, err
+	})
+}
@@ -0,0 +1,217 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultEndpoint is the Azure Resource Manager base URL
+const defaultEndpoint = "https://management.azure.com"
+
+// API versions of the Resource Manager operations used
+const (
+	subscriptionsAPIVersion = "2020-01-01"
+	dnsAPIVersion           = "2018-05-01"
+)
+
+// Config holds the settings of the azure config section
+type Config struct {
+	TenantID       string   `mapstructure:"tenant_id"`
+	ClientID       string   `mapstructure:"client_id"`
+	ClientSecret   string   `mapstructure:"client_secret"`
+	Subscriptions  []string `mapstructure:"subscriptions"`
+	ResourceGroups []string `mapstructure:"resource_groups"`
+	Endpoint       string   `mapstructure:"endpoint"`
+	Authority      string   `mapstructure:"authority"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "azure",
+		Description: "Azure DNS zones",
+		Settings: []provider.Setting{
+			{Key: "tenant_id", Env: "AZURE_TENANT_ID", Required: true, Description: "Directory (tenant) ID of the service principal"},
+			{Key: "client_id", Env: "AZURE_CLIENT_ID", Required: true, Description: "Application (client) ID of the service principal"},
+			{Key: "client_secret", Env: "AZURE_CLIENT_SECRET", Required: true, Description: "Client secret of the service principal"},
+			{Key: "subscriptions", Env: "AZURE_SUBSCRIPTION_ID", Description: "Subscriptions to list zones of (default: all visible)"},
+			{Key: "resource_groups", Description: "Only use zones in these resource groups"},
+			{Key: "endpoint", Description: "Resource Manager base URL"},
+			{Key: "authority", Description: "Login base URL"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// api lists DNS zones and record sets through the Resource Manager API
+type api struct {
+	client         *rest.Client
+	subscriptions  []string
+	resourceGroups map[string]bool
+}
+
+// dnsZone is an Azure DNS zone resource
+type dnsZone struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Properties struct {
+		ZoneType string `json:"zoneType"`
+	} `json:"properties"`
+}
+
+// recordSet is an Azure DNS record set resource
+type recordSet struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Properties struct {
+		FQDN string `json:"fqdn"`
+	} `json:"properties"`
+}
+
+// New creates a new Azure DNS provider
+func New(cfg Config) (*rest.Provider, error) {
+	if cfg.TenantID == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+		return nil, fmt.Errorf("tenant_id, client_id and client_secret are required")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	resourceGroups := make(map[string]bool, len(cfg.ResourceGroups))
+	for _, group := range cfg.ResourceGroups {
+		// Resource group names are case-insensitive
+		resourceGroups[strings.ToLower(group)] = true
+	}
+
+	tokens := newTokenSource(cfg.Authority, cfg.TenantID, cfg.ClientID, cfg.ClientSecret)
+	return rest.NewProvider("azure", &api{
+		client:         rest.NewClient(endpoint, tokens),
+		subscriptions:  cfg.Subscriptions,
+		resourceGroups: resourceGroups,
+	}), nil
+}
+
+// listSubscriptions returns the configured subscriptions, or all enabled
+// subscriptions the service principal can see
+func (a *api) listSubscriptions(ctx context.Context) ([]string, error) {
+	if len(a.subscriptions) > 0 {
+		return a.subscriptions, nil
+	}
+
+	subscriptions := make([]string, 0)
+	next, query := "/subscriptions", apiVersion(subscriptionsAPIVersion)
+	for next != "" {
+		var page struct {
+			Value []struct {
+				SubscriptionID string `json:"subscriptionId"`
+				State          string `json:"state"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if _, err := a.client.Get(ctx, next, query, &page); err != nil {
+			return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+		}
+		for _, sub := range page.Value {
+			if sub.State == "" || sub.State == "Enabled" {
+				subscriptions = append(subscriptions, sub.SubscriptionID)
+			}
+		}
+		next, query = page.NextLink, nil
+	}
+
+	if len(subscriptions) == 0 {
+		return nil, fmt.Errorf("no subscriptions visible to the service principal")
+	}
+	return subscriptions, nil
+}
+
+// Zones lists the public DNS zones of all subscriptions
+func (a *api) Zones(ctx context.Context) ([]rest.Zone, error) {
+	subscriptions, err := a.listSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	zones := make([]rest.Zone, 0)
+	for _, sub := range subscriptions {
+		next := "/subscriptions/" + url.PathEscape(sub) + "/providers/Microsoft.Network/dnszones"
+		query := apiVersion(dnsAPIVersion)
+		for next != "" {
+			var page struct {
+				Value    []dnsZone `json:"value"`
+				NextLink string    `json:"nextLink"`
+			}
+			if _, err := a.client.Get(ctx, next, query, &page); err != nil {
+				return nil, fmt.Errorf("subscription %s: %w", sub, err)
+			}
+			for _, zone := range page.Value {
+				if zone.Properties.ZoneType == "Private" || !a.inResourceGroup(zone.ID) {
+					continue
+				}
+				zones = append(zones, rest.Zone{ID: zone.ID, Name: zone.Name})
+			}
+			// Next links are absolute and carry the api-version
+			next, query = page.NextLink, nil
+		}
+	}
+	return zones, nil
+}
+
+// Records lists the record sets of a zone
+func (a *api) Records(ctx context.Context, zone rest.Zone) ([]provider.Record, error) {
+	records := make([]provider.Record, 0)
+	next, query := zone.ID+"/recordsets", apiVersion(dnsAPIVersion)
+	for next != "" {
+		var page struct {
+			Value    []recordSet `json:"value"`
+			NextLink string      `json:"nextLink"`
+		}
+		if _, err := a.client.Get(ctx, next, query, &page); err != nil {
+			return nil, err
+		}
+		for _, rs := range page.Value {
+			// Types look like "Microsoft.Network/dnszones/A"
+			recordType := rs.Type[strings.LastIndex(rs.Type, "/")+1:]
+			name := rs.Properties.FQDN
+			if name == "" {
+				name = provider.Qualify(rs.Name, zone.Name)
+			}
+			records = append(records, provider.Record{Name: name, Type: recordType})
+		}
+		next, query = page.NextLink, nil
+	}
+	return records, nil
+}
+
+// inResourceGroup reports whether a resource ID belongs to one of the
+// configured resource groups; all groups match when none are configured
+func (a *api) inResourceGroup(id string) bool {
+	if len(a.resourceGroups) == 0 {
+		return true
+	}
+
+	parts := strings.Split(id, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return a.resourceGroups[strings.ToLower(parts[i+1])]
+		}
+	}
+	return false
+}
+
+// apiVersion returns the query selecting a Resource Manager API version
+func apiVersion(version string) url.Values {
+	return url.Values{"api-version": {version}}
+}
@@ -3,11 +3,33 @@ package cloudflare
 import (
 	"context"
 	"fmt"
-	"strings"
 
 	"github.com/cloudflare/cloudflare-go"
+	"sslcheckdomain/internal/provider"
 )
 
+// Config holds the settings of the cloudflare config section
+type Config struct {
+	Token string `mapstructure:"token"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "cloudflare",
+		Description: "Cloudflare zones and DNS records",
+		Settings: []provider.Setting{
+			{Key: "token", Env: "CLOUDFLARE_API_TOKEN", Required: true, Description: "API token with Zone:Read and DNS:Read permissions"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg.Token)
+		},
+	})
+}
+
 // Provider implements the DNSProvider interface for Cloudflare
 type Provider struct {
 	client *cloudflare.API
@@ -89,29 +111,10 @@ func (p *Provider) getSubdomains(ctx context.Context, zoneID, zoneName string) (
 		return nil, fmt.Errorf("failed to list DNS records: %w", err)
 	}
 
-	// Use map to deduplicate domains
-	domainSet := make(map[string]bool)
-
+	hostRecords := make([]provider.Record, 0, len(records))
 	for _, record := range records {
-		// Only consider A, AAAA, and CNAME records that point to external resources
-		if record.Type == "A" || record.Type == "AAAA" || record.Type == "CNAME" {
-			// Skip if it's the zone apex
-			if record.Name == zoneName {
-				continue
-			}
-
-			// Only include if it's a subdomain and not a wildcard
-			if strings.HasSuffix(record.Name, "."+zoneName) && !strings.Contains(record.Name, "*") {
-				domainSet[record.Name] = true
-			}
-		}
-	}
-
-	// Convert map to slice
-	subdomains := make([]string, 0, len(domainSet))
-	for domain := range domainSet {
-		subdomains = append(subdomains, domain)
+		hostRecords = append(hostRecords, provider.Record{Name: record.Name, Type: record.Type})
 	}
 
-	return subdomains, nil
+	return provider.HostnamesWithWildcards(zoneName, hostRecords), nil
 }
@@ -0,0 +1,259 @@
+package ct
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/pkg/models"
+)
+
+// DefaultBaseURL is the crt.sh search endpoint
+const DefaultBaseURL = "https://crt.sh"
+
+// defaultTimeout bounds a search; crt.sh can be slow for large zones
+const defaultTimeout = 60 * time.Second
+
+// Config holds the settings of the ct config section
+type Config struct {
+	Zones           []string      `mapstructure:"zones"`
+	BaseURL         string        `mapstructure:"base_url"`
+	ExpectedIssuers []string      `mapstructure:"expected_issuers"`
+	IncludeExpired  bool          `mapstructure:"include_expired"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "ct",
+		Description: "Certificate Transparency log search (crt.sh compatible)",
+		Settings: []provider.Setting{
+			{Key: "zones", Env: "SSL_CHECK_CT_ZONES", Required: true, Description: "Zones to search certificates for (comma separated)"},
+			{Key: "base_url", Env: "SSL_CHECK_CT_URL", Description: "Search API base URL (default https://crt.sh)"},
+			{Key: "expected_issuers", Description: "Issuer names (substrings) that are expected; others are flagged"},
+			{Key: "include_expired", Description: "Also report names only found in expired certificates"},
+			{Key: "timeout", Description: "Search timeout (default 60s)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// Entry is a certificate returned by the search API
+type Entry struct {
+	ID         int64  `json:"id"`
+	IssuerName string `json:"issuer_name"`
+	CommonName string `json:"common_name"`
+	// NameValue holds the certificate's names separated by newlines
+	NameValue    string `json:"name_value"`
+	NotBefore    string `json:"not_before"`
+	NotAfter     string `json:"not_after"`
+	SerialNumber string `json:"serial_number"`
+}
+
+// Names returns the lower-cased names of the certificate
+func (e Entry) Names() []string {
+	names := make([]string, 0)
+	for _, name := range strings.Split(e.NameValue+"\n"+e.CommonName, "\n") {
+		if name = provider.NormalizeName(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Expired reports whether the certificate expired before now
+func (e Entry) Expired(now time.Time) bool {
+	notAfter, err := time.Parse("2006-01-02T15:04:05", e.NotAfter)
+	if err != nil {
+		return false
+	}
+	return notAfter.Before(now)
+}
+
+// Client queries a crt.sh compatible search API
+type Client struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a search client; an empty baseURL uses crt.sh
+func NewClient(baseURL string, timeout time.Duration) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Search returns the certificates logged for names below zone
+func (c *Client) Search(ctx context.Context, zone string) ([]Entry, error) {
+	query := url.Values{
+		"q":      {"%." + provider.NormalizeName(zone)},
+		"output": {"json"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("CT search failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("CT search returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var entries []Entry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("invalid CT search response: %w", err)
+	}
+	return entries, nil
+}
+
+// Provider implements the TargetProvider interface on top of CT logs
+type Provider struct {
+	client          *Client
+	zones           []string
+	expectedIssuers []string
+	includeExpired  bool
+}
+
+// New creates a new CT log provider
+func New(cfg Config) (*Provider, error) {
+	if len(cfg.Zones) == 0 {
+		return nil, fmt.Errorf("at least one zone is required")
+	}
+
+	return &Provider{
+		client:          NewClient(cfg.BaseURL, cfg.Timeout),
+		zones:           cfg.Zones,
+		expectedIssuers: cfg.ExpectedIssuers,
+		includeExpired:  cfg.IncludeExpired,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "ct"
+}
+
+// GetDomains retrieves the hostnames logged for all configured zones
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	targets, err := p.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetDomainsByZone retrieves the hostnames logged for a zone
+func (p *Provider) GetDomainsByZone(ctx context.Context, zone string) ([]string, error) {
+	targets, err := p.GetTargetsByZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetTargets retrieves the targets of all configured zones
+func (p *Provider) GetTargets(ctx context.Context) ([]models.Target, error) {
+	targets := make([]models.Target, 0)
+	for _, zone := range p.zones {
+		zoneTargets, err := p.GetTargetsByZone(ctx, zone)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, zoneTargets...)
+	}
+	return targets, nil
+}
+
+// GetTargetsByZone retrieves the hostnames below zone found in CT logs.
+// Hostnames with certificates from unexpected issuers carry a note.
+func (p *Provider) GetTargetsByZone(ctx context.Context, zone string) ([]models.Target, error) {
+	zone = provider.NormalizeName(zone)
+
+	entries, err := p.client.Search(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	issuers := make(map[string]map[string]bool)
+	for _, entry := range entries {
+		if !p.includeExpired && entry.Expired(now) {
+			continue
+		}
+		for _, name := range entry.Names() {
+			if name != zone && !strings.HasSuffix(name, "."+zone) {
+				continue
+			}
+			if issuers[name] == nil {
+				issuers[name] = make(map[string]bool)
+			}
+			if !p.expectedIssuer(entry.IssuerName) {
+				issuers[name][entry.IssuerName] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(issuers))
+	for name := range issuers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	targets := make([]models.Target, 0, len(names))
+	for _, name := range names {
+		target := models.Target{Domain: name}
+		unexpected := make([]string, 0, len(issuers[name]))
+		for issuer := range issuers[name] {
+			unexpected = append(unexpected, issuer)
+		}
+		sort.Strings(unexpected)
+		for _, issuer := range unexpected {
+			target.Notes = append(target.Notes, "issued by unexpected CA: "+issuer)
+		}
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+// expectedIssuer reports whether issuer matches one of the expected issuers
+func (p *Provider) expectedIssuer(issuer string) bool {
+	if len(p.expectedIssuers) == 0 {
+		return true
+	}
+	issuer = strings.ToLower(issuer)
+	for _, expected := range p.expectedIssuers {
+		if strings.Contains(issuer, strings.ToLower(expected)) {
+			return true
+		}
+	}
+	return false
+}
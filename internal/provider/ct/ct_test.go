@@ -0,0 +1,127 @@
+package ct
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// newSearchServer serves entries for the search q=%.example.test
+func newSearchServer(t *testing.T, entries []Entry) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		if q := r.URL.Query().Get("q"); q != "%.example.test" {
+			t.Errorf("q = %q, want %q", q, "%.example.test")
+		}
+		if output := r.URL.Query().Get("output"); output != "json" {
+			t.Errorf("output = %q, want json", output)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(entries)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testEntries returns CT entries below example.test, one of them expired
+func testEntries() []Entry {
+	valid := time.Now().AddDate(0, 2, 0).UTC().Format("2006-01-02T15:04:05")
+	expired := time.Now().AddDate(0, -2, 0).UTC().Format("2006-01-02T15:04:05")
+
+	return []Entry{
+		{ID: 1, IssuerName: "C=US, O=Let's Encrypt, CN=R3", CommonName: "www.example.test", NameValue: "www.example.test\nexample.test", NotAfter: valid},
+		{ID: 2, IssuerName: "C=US, O=Shady CA, CN=Shady", CommonName: "API.example.test", NameValue: "api.example.test", NotAfter: valid},
+		{ID: 3, IssuerName: "C=US, O=Let's Encrypt, CN=R3", CommonName: "old.example.test", NameValue: "old.example.test", NotAfter: expired},
+		{ID: 4, IssuerName: "C=US, O=Let's Encrypt, CN=R3", CommonName: "example.test.evil", NameValue: "other.test", NotAfter: valid},
+	}
+}
+
+func TestSearch(t *testing.T) {
+	server := newSearchServer(t, testEntries())
+
+	entries, err := NewClient(server.URL+"/", time.Second).Search(context.Background(), "Example.Test.")
+	if err != nil {
+		t.Fatalf("Search() error = %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("Search() returned %d entries, want 4", len(entries))
+	}
+
+	want := []string{"www.example.test", "example.test", "www.example.test"}
+	if names := entries[0].Names(); !reflect.DeepEqual(names, want) {
+		t.Errorf("Names() = %v, want %v", names, want)
+	}
+	if entries[0].Expired(time.Now()) || !entries[2].Expired(time.Now()) {
+		t.Errorf("Expired() does not match not_after")
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "upstream overloaded", http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := NewClient(server.URL, time.Second).Search(context.Background(), "example.test")
+	if err == nil || !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "upstream overloaded") {
+		t.Errorf("Search() error = %v, want the status and body", err)
+	}
+}
+
+func TestGetTargetsByZone(t *testing.T) {
+	server := newSearchServer(t, testEntries())
+
+	p, err := New(Config{
+		Zones:           []string{"example.test"},
+		BaseURL:         server.URL,
+		ExpectedIssuers: []string{"Let's Encrypt"},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	targets, err := p.GetTargetsByZone(context.Background(), "example.test")
+	if err != nil {
+		t.Fatalf("GetTargetsByZone() error = %v", err)
+	}
+
+	want := []models.Target{
+		{Domain: "api.example.test", Notes: []string{"issued by unexpected CA: C=US, O=Shady CA, CN=Shady"}},
+		{Domain: "example.test"},
+		{Domain: "www.example.test"},
+	}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("GetTargetsByZone() = %+v, want %+v", targets, want)
+	}
+}
+
+func TestGetTargetsIncludeExpired(t *testing.T) {
+	server := newSearchServer(t, testEntries())
+
+	p, err := New(Config{Zones: []string{"example.test"}, BaseURL: server.URL, IncludeExpired: true})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	domains, err := p.GetDomains(context.Background())
+	if err != nil {
+		t.Fatalf("GetDomains() error = %v", err)
+	}
+
+	want := []string{"api.example.test", "example.test", "old.example.test", "www.example.test"}
+	if !reflect.DeepEqual(domains, want) {
+		t.Errorf("GetDomains() = %v, want %v", domains, want)
+	}
+}
@@ -0,0 +1,120 @@
+package digitalocean
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultEndpoint is the DigitalOcean API base URL
+const defaultEndpoint = "https://api.digitalocean.com/v2"
+
+// perPage is the page size requested from the API (maximum 200)
+const perPage = "200"
+
+// Config holds the settings of the digitalocean config section
+type Config struct {
+	Token    string `mapstructure:"token"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "digitalocean",
+		Description: "DigitalOcean DNS domains",
+		Settings: []provider.Setting{
+			{Key: "token", Env: "DIGITALOCEAN_TOKEN", Required: true, Description: "Personal access token with read scope"},
+			{Key: "endpoint", Description: "API base URL"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// api lists domains and records through the DigitalOcean API
+type api struct {
+	client *rest.Client
+}
+
+// links holds the pagination links of a list response
+type links struct {
+	Pages struct {
+		Next string `json:"next"`
+	} `json:"pages"`
+}
+
+// New creates a new DigitalOcean DNS provider
+func New(cfg Config) (*rest.Provider, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	return rest.NewProvider("digitalocean", &api{
+		client: rest.NewClient(endpoint, rest.Bearer(cfg.Token)),
+	}), nil
+}
+
+// Zones lists the domains of the account
+func (a *api) Zones(ctx context.Context) ([]rest.Zone, error) {
+	zones := make([]rest.Zone, 0)
+	next, query := "/domains", url.Values{"per_page": {perPage}}
+	for next != "" {
+		var page struct {
+			Domains []struct {
+				Name string `json:"name"`
+			} `json:"domains"`
+			Links links `json:"links"`
+		}
+		if _, err := a.client.Get(ctx, next, query, &page); err != nil {
+			return nil, err
+		}
+
+		for _, domain := range page.Domains {
+			zones = append(zones, rest.Zone{ID: domain.Name, Name: domain.Name})
+		}
+		// Next links are absolute and carry the page size
+		next, query = page.Links.Pages.Next, nil
+	}
+	return zones, nil
+}
+
+// Records lists the records of a domain
+func (a *api) Records(ctx context.Context, zone rest.Zone) ([]provider.Record, error) {
+	records := make([]provider.Record, 0)
+	next := "/domains/" + url.PathEscape(zone.ID) + "/records"
+	query := url.Values{"per_page": {perPage}}
+	for next != "" {
+		var page struct {
+			Records []struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"domain_records"`
+			Links links `json:"links"`
+		}
+		if _, err := a.client.Get(ctx, next, query, &page); err != nil {
+			return nil, err
+		}
+
+		for _, record := range page.Records {
+			records = append(records, provider.Record{
+				Name: provider.Qualify(record.Name, zone.Name),
+				Type: record.Type,
+			})
+		}
+		next, query = page.Links.Pages.Next, nil
+	}
+	return records, nil
+}
@@ -0,0 +1,268 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"sslcheckdomain/internal/inventory"
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/pkg/models"
+)
+
+// defaultTimeout bounds a single run of the command
+const defaultTimeout = 60 * time.Second
+
+// maxStdout is the largest target list accepted from the command
+const maxStdout = 16 << 20
+
+// maxStderr is the amount of stderr output kept for error messages
+const maxStderr = 4096
+
+// waitDelay bounds how long output is awaited after the command was killed,
+// in case processes it started still hold stdout or stderr open
+const waitDelay = 2 * time.Second
+
+// Config holds the settings of the exec config section
+type Config struct {
+	Command []string      `mapstructure:"command"`
+	Dir     string        `mapstructure:"dir"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "exec",
+		Description: "External command printing a JSON target list",
+		Settings: []provider.Setting{
+			{Key: "command", Env: "SSL_CHECK_EXEC_COMMAND", Required: true, Description: "Command and arguments to run"},
+			{Key: "dir", Description: "Working directory of the command"},
+			{Key: "timeout", Description: "Command timeout (default 60s)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			// A single string is split into words like a shell would,
+			// rather than on commas
+			if command, ok := settings["command"].(string); ok {
+				words, err := splitWords(command)
+				if err != nil {
+					return nil, fmt.Errorf("invalid command: %w", err)
+				}
+				settings["command"] = words
+			}
+
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// Provider implements the TargetProvider interface by running a command.
+// The command prints the targets to stdout in the JSON format of domain
+// lists, and receives the requested zone in SSL_CHECK_ZONE.
+type Provider struct {
+	command []string
+	dir     string
+	timeout time.Duration
+}
+
+// New creates a new exec provider
+func New(cfg Config) (*Provider, error) {
+	if len(cfg.Command) == 0 {
+		return nil, fmt.Errorf("command is required")
+	}
+
+	p := &Provider{
+		command: cfg.Command,
+		dir:     cfg.Dir,
+		timeout: cfg.Timeout,
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultTimeout
+	}
+	return p, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "exec"
+}
+
+// GetDomains retrieves all hostnames printed by the command
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	targets, err := p.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetDomainsByZone retrieves the hostnames below zone printed by the command
+func (p *Provider) GetDomainsByZone(ctx context.Context, zone string) ([]string, error) {
+	targets, err := p.GetTargetsByZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetTargets runs the command and returns its targets
+func (p *Provider) GetTargets(ctx context.Context) ([]models.Target, error) {
+	return p.run(ctx, "")
+}
+
+// GetTargetsByZone runs the command for zone and returns the targets below it
+func (p *Provider) GetTargetsByZone(ctx context.Context, zone string) ([]models.Target, error) {
+	zone = provider.NormalizeName(zone)
+
+	targets, err := p.run(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]models.Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Domain == zone || strings.HasSuffix(target.Domain, "."+zone) {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered, nil
+}
+
+// run executes the command and parses its output
+func (p *Provider) run(ctx context.Context, zone string) ([]models.Target, error) {
+	runCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(runCtx, p.command[0], p.command[1:]...)
+	cmd.Dir = p.dir
+	cmd.Env = append(os.Environ(), "SSL_CHECK_ZONE="+zone)
+	cmd.WaitDelay = waitDelay
+	configureProcess(cmd)
+
+	stdout := &limitedBuffer{limit: maxStdout}
+	stderr := &limitedBuffer{limit: maxStderr}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(runCtx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", p.timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("command %s failed: %w: %s", p.command[0], err, msg)
+		}
+		return nil, fmt.Errorf("command %s failed: %w", p.command[0], err)
+	}
+
+	// Warnings of inventory scripts are passed on rather than dropped
+	if msg := strings.TrimSpace(stderr.String()); msg != "" {
+		log.Printf("command %s: %s", p.command[0], msg)
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("command %s: output exceeds %d bytes", p.command[0], maxStdout)
+	}
+
+	targets, err := inventory.Parse(&stdout.buf, inventory.FormatJSON)
+	if err != nil {
+		return nil, fmt.Errorf("command %s: %w", p.command[0], err)
+	}
+	return targets, nil
+}
+
+// limitedBuffer keeps the first limit bytes written to it
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write stores up to the limit and discards the rest
+func (b *limitedBuffer) Write(data []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(data) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(data[:remaining])
+		}
+	} else {
+		b.buf.Write(data)
+	}
+	return len(data), nil
+}
+
+// String returns the captured output
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
+
+// splitWords splits a command line into words using the quoting rules of a
+// POSIX shell: single quotes keep everything literally, double quotes and
+// backslashes escape as usual. Variables and globs are not expanded.
+func splitWords(line string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			// Inside double quotes a backslash only escapes \, ", $ and `
+			if quote == '"' && !strings.ContainsRune("\\\"$`", r) {
+				word.WriteRune('\\')
+			}
+			word.WriteRune(r)
+			escaped = false
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\\':
+			escaped = true
+			inWord = true
+		case quote == '"':
+			if r == '"' {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	switch {
+	case escaped:
+		return nil, fmt.Errorf("trailing backslash")
+	case quote != 0:
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package exec
+
+import "os/exec"
+
+// configureProcess keeps the default of killing only the command itself;
+// the wait delay still bounds how long its output is awaited
+func configureProcess(cmd *exec.Cmd) {}
@@ -0,0 +1,101 @@
+package exec
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"sslcheckdomain/pkg/models"
+)
+
+// shell returns a provider running script with sh
+func shell(t *testing.T, script string, timeout time.Duration) *Provider {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+	p, err := New(Config{Command: []string{"sh", "-c", script}, Timeout: timeout})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return p
+}
+
+func TestGetTargetsByZone(t *testing.T) {
+	p := shell(t, `echo '["www.example.test", {"domain": "api.example.test", "port": 8443}, "other.test"]'; echo "zone=$SSL_CHECK_ZONE" >&2`, time.Second)
+
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	targets, err := p.GetTargetsByZone(context.Background(), "Example.Test.")
+	if err != nil {
+		t.Fatalf("GetTargetsByZone() error = %v", err)
+	}
+
+	want := []models.Target{{Domain: "www.example.test"}, {Domain: "api.example.test", Port: 8443}}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("GetTargetsByZone() = %+v, want %+v", targets, want)
+	}
+	if !strings.Contains(logged.String(), "zone=example.test") {
+		t.Errorf("stderr of a successful run was not logged, got %q", logged.String())
+	}
+}
+
+func TestTimeoutKillsChildren(t *testing.T) {
+	// The shell's sleep keeps stdout open after the shell itself is killed
+	p := shell(t, `sleep 20; echo '[]'`, 200*time.Millisecond)
+
+	start := time.Now()
+	_, err := p.GetTargets(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out after 200ms") {
+		t.Errorf("GetTargets() error = %v, want a timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("GetTargets() returned after %s, want prompt return on timeout", elapsed)
+	}
+}
+
+func TestOutputLimit(t *testing.T) {
+	p := shell(t, `head -c 20000000 /dev/zero`, 10*time.Second)
+
+	_, err := p.GetTargets(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "output exceeds") {
+		t.Errorf("GetTargets() error = %v, want the output limit", err)
+	}
+}
+
+func TestSplitWords(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+	}{
+		{`inventory --env prod`, []string{"inventory", "--env", "prod"}},
+		{`"/opt/my scripts/inv" 'a b' c\ d`, []string{"/opt/my scripts/inv", "a b", "c d"}},
+		{`echo "a\"b\n" ''`, []string{"echo", `a"b\n`, ""}},
+	}
+
+	for _, tt := range tests {
+		got, err := splitWords(tt.line)
+		if err != nil {
+			t.Errorf("splitWords(%q) error = %v", tt.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitWords(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+
+	for _, line := range []string{`echo "open`, `echo 'open`, `echo \`} {
+		if _, err := splitWords(line); err == nil {
+			t.Errorf("splitWords(%q) error = nil, want an error", line)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package gandi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultEndpoint is the Gandi LiveDNS API base URL
+const defaultEndpoint = "https://api.gandi.net/v5/livedns"
+
+// perPage is the page size requested from the API
+const perPage = 100
+
+// Config holds the settings of the gandi config section
+type Config struct {
+	Token    string `mapstructure:"token"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "gandi",
+		Description: "Gandi LiveDNS domains",
+		Settings: []provider.Setting{
+			{Key: "token", Env: "GANDI_PAT", Required: true, Description: "Personal access token with LiveDNS read permission"},
+			{Key: "endpoint", Description: "API base URL"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// api lists domains and records through the LiveDNS API
+type api struct {
+	client *rest.Client
+}
+
+// New creates a new Gandi LiveDNS provider
+func New(cfg Config) (*rest.Provider, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("personal access token is required")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	return rest.NewProvider("gandi", &api{
+		client: rest.NewClient(endpoint, rest.Bearer(cfg.Token)),
+	}), nil
+}
+
+// Zones lists the LiveDNS domains of the account
+func (a *api) Zones(ctx context.Context) ([]rest.Zone, error) {
+	zones := make([]rest.Zone, 0)
+	for page := 1; ; page++ {
+		var domains []struct {
+			FQDN string `json:"fqdn"`
+		}
+		header, err := a.client.Get(ctx, "/domains", pageQuery(page), &domains)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, domain := range domains {
+			zones = append(zones, rest.Zone{ID: domain.FQDN, Name: domain.FQDN})
+		}
+		if lastPage(header, page, len(domains)) {
+			return zones, nil
+		}
+	}
+}
+
+// Records lists the records of a domain
+func (a *api) Records(ctx context.Context, zone rest.Zone) ([]provider.Record, error) {
+	path := "/domains/" + url.PathEscape(zone.ID) + "/records"
+
+	records := make([]provider.Record, 0)
+	for page := 1; ; page++ {
+		var rrsets []struct {
+			Name string `json:"rrset_name"`
+			Type string `json:"rrset_type"`
+		}
+		header, err := a.client.Get(ctx, path, pageQuery(page), &rrsets)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rrset := range rrsets {
+			records = append(records, provider.Record{
+				Name: provider.Qualify(rrset.Name, zone.Name),
+				Type: rrset.Type,
+			})
+		}
+		if lastPage(header, page, len(rrsets)) {
+			return records, nil
+		}
+	}
+}
+
+// lastPage reports whether page is the last one. LiveDNS returns the total
+// number of items in the Total-Count header; without it a short page ends
+// the listing.
+func lastPage(header http.Header, page, items int) bool {
+	if total, err := strconv.Atoi(header.Get("Total-Count")); err == nil {
+		return page*perPage >= total
+	}
+	return items < perPage
+}
+
+// pageQuery returns the query selecting a result page
+func pageQuery(page int) url.Values {
+	return url.Values{
+		"page":     {strconv.Itoa(page)},
+		"per_page": {strconv.Itoa(perPage)},
+	}
+}
@@ -0,0 +1,145 @@
+package gcloud
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultTokenURL is Google's OAuth 2.0 token endpoint
+const defaultTokenURL = "https://oauth2.googleapis.com/token"
+
+// readOnlyScope grants read access to Cloud DNS
+const readOnlyScope = "https://www.googleapis.com/auth/ndev.clouddns.readonly"
+
+// serviceAccount is the subset of a service account JSON key used here
+type serviceAccount struct {
+	Type         string `json:"type"`
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+}
+
+// loadServiceAccount reads a service account JSON key file
+func loadServiceAccount(path string) (*serviceAccount, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	var sa serviceAccount
+	if err := json.Unmarshal(data, &sa); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+	if sa.Type != "service_account" {
+		return nil, fmt.Errorf("credentials must be a service account key, got %q", sa.Type)
+	}
+	if sa.ClientEmail == "" || sa.PrivateKey == "" {
+		return nil, fmt.Errorf("credentials are missing client_email or private_key")
+	}
+	return &sa, nil
+}
+
+// signer creates the signed JWT assertions of a service account
+type signer struct {
+	email    string
+	keyID    string
+	key      *rsa.PrivateKey
+	tokenURL string
+}
+
+// newTokenSource creates a token source that exchanges signed assertions
+// of the service account for access tokens
+func newTokenSource(sa *serviceAccount, tokenURL string) (*rest.TokenSource, error) {
+	block, _ := pem.Decode([]byte(sa.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("invalid private key in credentials")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key in credentials: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key in credentials is not an RSA key")
+	}
+
+	if tokenURL == "" {
+		tokenURL = sa.TokenURI
+	}
+	if tokenURL == "" {
+		tokenURL = defaultTokenURL
+	}
+
+	s := &signer{
+		email:    sa.ClientEmail,
+		keyID:    sa.PrivateKeyID,
+		key:      key,
+		tokenURL: tokenURL,
+	}
+	client := rest.NewClient(tokenURL, nil)
+
+	return rest.NewTokenSource(func(ctx context.Context) (rest.Token, error) {
+		var token rest.Token
+		assertion, err := s.assertion(time.Now())
+		if err != nil {
+			return token, err
+		}
+		err = client.PostForm(ctx, "", url.Values{
+			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+			"assertion":  {assertion},
+		}, &token)
+		return token, err
+	}), nil
+}
+
+// assertion builds the RS256 signed JWT for the token request
+func (s *signer) assertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": s.keyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   s.email,
+		"scope": readOnlyScope,
+		"aud":   s.tokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token request: %w", err)
+	}
+
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
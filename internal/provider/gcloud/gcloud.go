@@ -0,0 +1,162 @@
+package gcloud
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultEndpoint is the Cloud DNS API base URL
+const defaultEndpoint = "https://dns.googleapis.com/dns/v1"
+
+// Config holds the settings of the gcloud config section
+type Config struct {
+	CredentialsFile string   `mapstructure:"credentials_file"`
+	Projects        []string `mapstructure:"projects"`
+	Endpoint        string   `mapstructure:"endpoint"`
+	TokenURL        string   `mapstructure:"token_url"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "gcloud",
+		Description: "Google Cloud DNS managed zones",
+		Settings: []provider.Setting{
+			{Key: "credentials_file", Env: "GOOGLE_APPLICATION_CREDENTIALS", Required: true, Description: "Service account JSON key with DNS Reader role"},
+			{Key: "projects", Env: "SSL_CHECK_GCLOUD_PROJECTS", Description: "Projects to list zones of (default: project of the key)"},
+			{Key: "endpoint", Description: "Cloud DNS API base URL"},
+			{Key: "token_url", Description: "OAuth 2.0 token URL (default: token_uri of the key)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// api lists managed zones and record sets through the Cloud DNS API
+type api struct {
+	client   *rest.Client
+	projects []string
+}
+
+// managedZone is a Cloud DNS managed zone
+type managedZone struct {
+	Name       string `json:"name"`
+	DNSName    string `json:"dnsName"`
+	Visibility string `json:"visibility"`
+}
+
+// resourceRecordSet is a Cloud DNS record set
+type resourceRecordSet struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// New creates a new Google Cloud DNS provider
+func New(cfg Config) (*rest.Provider, error) {
+	if cfg.CredentialsFile == "" {
+		return nil, fmt.Errorf("service account credentials are required")
+	}
+
+	sa, err := loadServiceAccount(cfg.CredentialsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := cfg.Projects
+	if len(projects) == 0 && sa.ProjectID != "" {
+		projects = []string{sa.ProjectID}
+	}
+	if len(projects) == 0 {
+		return nil, fmt.Errorf("no projects configured and the credentials have no project_id")
+	}
+
+	tokens, err := newTokenSource(sa, cfg.TokenURL)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	return rest.NewProvider("gcloud", &api{
+		client:   rest.NewClient(endpoint, tokens),
+		projects: projects,
+	}), nil
+}
+
+// Zones lists the public managed zones of all projects. Private zones are
+// skipped as their hosts are not reachable from the outside.
+func (a *api) Zones(ctx context.Context) ([]rest.Zone, error) {
+	zones := make([]rest.Zone, 0)
+	for _, project := range a.projects {
+		path := "/projects/" + url.PathEscape(project) + "/managedZones"
+		pageToken := ""
+		for {
+			var page struct {
+				ManagedZones  []managedZone `json:"managedZones"`
+				NextPageToken string        `json:"nextPageToken"`
+			}
+			if _, err := a.client.Get(ctx, path, pageQuery(pageToken), &page); err != nil {
+				return nil, fmt.Errorf("project %s: %w", project, err)
+			}
+
+			for _, zone := range page.ManagedZones {
+				if zone.Visibility == "private" {
+					continue
+				}
+				zones = append(zones, rest.Zone{
+					ID:   path + "/" + url.PathEscape(zone.Name),
+					Name: zone.DNSName,
+				})
+			}
+
+			if page.NextPageToken == "" {
+				break
+			}
+			pageToken = page.NextPageToken
+		}
+	}
+	return zones, nil
+}
+
+// Records lists the record sets of a managed zone
+func (a *api) Records(ctx context.Context, zone rest.Zone) ([]provider.Record, error) {
+	records := make([]provider.Record, 0)
+	pageToken := ""
+	for {
+		var page struct {
+			RRSets        []resourceRecordSet `json:"rrsets"`
+			NextPageToken string              `json:"nextPageToken"`
+		}
+		if _, err := a.client.Get(ctx, zone.ID+"/rrsets", pageQuery(pageToken), &page); err != nil {
+			return nil, err
+		}
+
+		for _, rrset := range page.RRSets {
+			records = append(records, provider.Record{Name: rrset.Name, Type: rrset.Type})
+		}
+
+		if page.NextPageToken == "" {
+			return records, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
+// pageQuery returns the query selecting a result page
+func pageQuery(pageToken string) url.Values {
+	if pageToken == "" {
+		return nil
+	}
+	return url.Values{"pageToken": {pageToken}}
+}
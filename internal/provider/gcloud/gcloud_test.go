@@ -0,0 +1,250 @@
+package gcloud
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+const testToken = "test-access-token"
+
+// fakeCloudDNS is an HTTP stand-in for the OAuth token endpoint and the
+// Cloud DNS API of project "proj"
+type fakeCloudDNS struct {
+	t         *testing.T
+	key       *rsa.PublicKey
+	server    *httptest.Server
+	tokenURL  string
+	tokenHits atomic.Int32
+}
+
+func newFakeCloudDNS(t *testing.T, key *rsa.PublicKey) *fakeCloudDNS {
+	f := &fakeCloudDNS{t: t, key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", f.handleToken)
+	mux.HandleFunc("/dns/v1/projects/proj/managedZones", f.authorized(f.handleZones))
+	mux.HandleFunc("/dns/v1/projects/proj/managedZones/public/rrsets", f.authorized(f.handleRecords))
+	mux.HandleFunc("/dns/v1/projects/proj/managedZones/broken/rrsets", f.authorized(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"internal"}}`, http.StatusInternalServerError)
+	}))
+
+	f.server = httptest.NewServer(mux)
+	f.tokenURL = f.server.URL + "/token"
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// handleToken checks the signed JWT assertion and issues testToken
+func (f *fakeCloudDNS) handleToken(w http.ResponseWriter, r *http.Request) {
+	f.tokenHits.Add(1)
+	if err := r.ParseForm(); err != nil || r.Method != http.MethodPost {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if grant := r.PostForm.Get("grant_type"); grant != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+		f.t.Errorf("grant_type = %q", grant)
+	}
+
+	parts := strings.Split(r.PostForm.Get("assertion"), ".")
+	if len(parts) != 3 {
+		http.Error(w, "malformed assertion", http.StatusBadRequest)
+		return
+	}
+	signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(f.key, crypto.SHA256, digest[:], signature); err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var claims struct {
+		Iss   string `json:"iss"`
+		Scope string `json:"scope"`
+		Aud   string `json:"aud"`
+	}
+	payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		http.Error(w, "invalid claims", http.StatusBadRequest)
+		return
+	}
+	if claims.Iss != "dns-reader@proj.iam.gserviceaccount.com" || claims.Scope != readOnlyScope || claims.Aud != f.tokenURL {
+		f.t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"access_token": testToken, "expires_in": 3600})
+}
+
+// authorized rejects requests without the bearer token
+func (f *fakeCloudDNS) authorized(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer "+testToken {
+			http.Error(w, `{"error":{"message":"unauthenticated"}}`, http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleZones lists the zones on two pages
+func (f *fakeCloudDNS) handleZones(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("pageToken") {
+	case "":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"managedZones": []managedZone{
+				{Name: "public", DNSName: "example.test.", Visibility: "public"},
+				{Name: "internal", DNSName: "corp.internal.", Visibility: "private"},
+			},
+			"nextPageToken": "page2",
+		})
+	case "page2":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"managedZones": []managedZone{{Name: "broken", DNSName: "broken.test.", Visibility: "public"}},
+		})
+	default:
+		http.Error(w, "bad page token", http.StatusBadRequest)
+	}
+}
+
+// handleRecords lists the record sets of the public zone on two pages
+func (f *fakeCloudDNS) handleRecords(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Query().Get("pageToken") {
+	case "":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rrsets": []resourceRecordSet{
+				{Name: "example.test.", Type: "SOA"},
+				{Name: "example.test.", Type: "A"},
+				{Name: "www.example.test.", Type: "A"},
+			},
+			"nextPageToken": "next",
+		})
+	case "next":
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rrsets": []resourceRecordSet{
+				{Name: "api.example.test.", Type: "CNAME"},
+				{Name: "example.test.", Type: "MX"},
+				{Name: "*.apps.example.test.", Type: "A"},
+			},
+		})
+	default:
+		http.Error(w, "bad page token", http.StatusBadRequest)
+	}
+}
+
+// writeCredentials writes a service account key file and returns its path
+// and public key
+func writeCredentials(t *testing.T) (string, *rsa.PublicKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	data, err := json.Marshal(serviceAccount{
+		Type:         "service_account",
+		ProjectID:    "proj",
+		PrivateKeyID: "key-1",
+		PrivateKey:   string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		ClientEmail:  "dns-reader@proj.iam.gserviceaccount.com",
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal credentials: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("failed to write credentials: %v", err)
+	}
+	return path, &key.PublicKey
+}
+
+func TestGetDomains(t *testing.T) {
+	credentials, key := writeCredentials(t)
+	fake := newFakeCloudDNS(t, key)
+
+	p, err := New(Config{
+		CredentialsFile: credentials,
+		Endpoint:        fake.server.URL + "/dns/v1",
+		TokenURL:        fake.tokenURL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// The private zone is skipped and the broken zone is left out
+	domains, err := p.GetDomains(context.Background())
+	if err != nil {
+		t.Fatalf("GetDomains() error = %v", err)
+	}
+	want := []string{"example.test", "www.example.test", "api.example.test", "*.apps.example.test"}
+	if !reflect.DeepEqual(domains, want) {
+		t.Errorf("GetDomains() = %v, want %v", domains, want)
+	}
+
+	// The access token is cached across requests
+	if _, err := p.GetDomainsByZone(context.Background(), "Example.Test."); err != nil {
+		t.Fatalf("GetDomainsByZone() error = %v", err)
+	}
+	if hits := fake.tokenHits.Load(); hits != 1 {
+		t.Errorf("token endpoint called %d times, want 1", hits)
+	}
+}
+
+func TestGetDomainsByZoneErrors(t *testing.T) {
+	credentials, key := writeCredentials(t)
+	fake := newFakeCloudDNS(t, key)
+
+	p, err := New(Config{
+		CredentialsFile: credentials,
+		Endpoint:        fake.server.URL + "/dns/v1",
+		TokenURL:        fake.tokenURL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.GetDomainsByZone(context.Background(), "corp.internal"); err == nil || !strings.Contains(err.Error(), "zone not found") {
+		t.Errorf("GetDomainsByZone(private zone) error = %v, want zone not found", err)
+	}
+	if _, err := p.GetDomainsByZone(context.Background(), "broken.test"); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("GetDomainsByZone(broken zone) error = %v, want the API error", err)
+	}
+}
+
+func TestInvalidSignature(t *testing.T) {
+	credentials, _ := writeCredentials(t)
+	_, otherKey := writeCredentials(t)
+	fake := newFakeCloudDNS(t, otherKey)
+
+	p, err := New(Config{
+		CredentialsFile: credentials,
+		Endpoint:        fake.server.URL + "/dns/v1",
+		TokenURL:        fake.tokenURL,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if _, err := p.GetDomains(context.Background()); err == nil || !strings.Contains(err.Error(), "token request failed") {
+		t.Errorf("GetDomains() error = %v, want a token error", err)
+	}
+}
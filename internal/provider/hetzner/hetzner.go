@@ -0,0 +1,132 @@
+package hetzner
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/provider/rest"
+)
+
+// defaultEndpoint is the Hetzner DNS API base URL
+const defaultEndpoint = "https://dns.hetzner.com/api/v1"
+
+// perPage is the page size requested from the API
+const perPage = 100
+
+// Config holds the settings of the hetzner config section
+type Config struct {
+	Token    string `mapstructure:"token"`
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "hetzner",
+		Description: "Hetzner DNS zones",
+		Settings: []provider.Setting{
+			{Key: "token", Env: "HETZNER_DNS_TOKEN", Required: true, Description: "DNS API token"},
+			{Key: "endpoint", Description: "API base URL"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// api lists zones and records through the Hetzner DNS API
+type api struct {
+	client *rest.Client
+}
+
+// meta holds the pagination details of a list response
+type meta struct {
+	Pagination struct {
+		Page     int `json:"page"`
+		LastPage int `json:"last_page"`
+	} `json:"pagination"`
+}
+
+// New creates a new Hetzner DNS provider
+func New(cfg Config) (*rest.Provider, error) {
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("API token is required")
+	}
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+
+	return rest.NewProvider("hetzner", &api{
+		client: rest.NewClient(endpoint, rest.Header("Auth-API-Token", cfg.Token)),
+	}), nil
+}
+
+// Zones lists the zones of the account
+func (a *api) Zones(ctx context.Context) ([]rest.Zone, error) {
+	zones := make([]rest.Zone, 0)
+	for page := 1; ; page++ {
+		var resp struct {
+			Zones []struct {
+				ID   string `json:"id"`
+				Name string `json:"name"`
+			} `json:"zones"`
+			Meta meta `json:"meta"`
+		}
+		if _, err := a.client.Get(ctx, "/zones", pageQuery(page), &resp); err != nil {
+			return nil, err
+		}
+
+		for _, zone := range resp.Zones {
+			zones = append(zones, rest.Zone{ID: zone.ID, Name: zone.Name})
+		}
+		if page >= resp.Meta.Pagination.LastPage {
+			return zones, nil
+		}
+	}
+}
+
+// Records lists the records of a zone
+func (a *api) Records(ctx context.Context, zone rest.Zone) ([]provider.Record, error) {
+	records := make([]provider.Record, 0)
+	for page := 1; ; page++ {
+		query := pageQuery(page)
+		query.Set("zone_id", zone.ID)
+
+		var resp struct {
+			Records []struct {
+				Type string `json:"type"`
+				Name string `json:"name"`
+			} `json:"records"`
+			Meta meta `json:"meta"`
+		}
+		if _, err := a.client.Get(ctx, "/records", query, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, record := range resp.Records {
+			records = append(records, provider.Record{
+				Name: provider.Qualify(record.Name, zone.Name),
+				Type: record.Type,
+			})
+		}
+		if page >= resp.Meta.Pagination.LastPage {
+			return records, nil
+		}
+	}
+}
+
+// pageQuery returns the query selecting a result page
+func pageQuery(page int) url.Values {
+	return url.Values{
+		"page":     {strconv.Itoa(page)},
+		"per_page": {strconv.Itoa(perPage)},
+	}
+}
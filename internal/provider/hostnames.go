@@ -0,0 +1,91 @@
+package provider
+
+import (
+	"strings"
+)
+
+// Record is the part of a DNS record used for hostname discovery
+type Record struct {
+	Name string
+	Type string
+}
+
+// Hostnames returns the subdomains of zone that should be checked.
+// Only A, AAAA and CNAME records are considered; the zone apex and
+// wildcard records are skipped. Names are compared case-insensitively
+// and may be fully qualified with a trailing dot.
+func Hostnames(zone string, records []Record) []string {
+	return hostnames(zone, records, false)
+}
+
+// HostnamesWithWildcards is like Hostnames, but returns wildcard records as
+// is (e.g. "*.example.com") so that discovery can expand them to concrete
+// hostnames
+func HostnamesWithWildcards(zone string, records []Record) []string {
+	return hostnames(zone, records, true)
+}
+
+// hostnames implements Hostnames and HostnamesWithWildcards
+func hostnames(zone string, records []Record, wildcards bool) []string {
+	zone = NormalizeName(zone)
+
+	// Use map to deduplicate domains
+	domainSet := make(map[string]bool)
+	subdomains := make([]string, 0)
+
+	for _, record := range records {
+		// Only consider A, AAAA, and CNAME records that point to external resources
+		switch strings.ToUpper(record.Type) {
+		case "A", "AAAA", "CNAME":
+		default:
+			continue
+		}
+
+		name := NormalizeName(record.Name)
+
+		// Skip if it's the zone apex
+		if name == zone {
+			continue
+		}
+
+		// Only a leading wildcard label can be expanded
+		if strings.Contains(name, "*") {
+			if !wildcards || !IsWildcard(name) || strings.Contains(name[1:], "*") {
+				continue
+			}
+		}
+
+		// Only include if it's a subdomain
+		if strings.HasSuffix(name, "."+zone) && !domainSet[name] {
+			domainSet[name] = true
+			subdomains = append(subdomains, name)
+		}
+	}
+
+	return subdomains
+}
+
+// NormalizeName lower-cases a DNS name and strips the trailing root dot
+func NormalizeName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// IsWildcard reports whether name is a wildcard name such as *.example.com
+func IsWildcard(name string) bool {
+	return strings.HasPrefix(name, "*.")
+}
+
+// Qualify turns a record name relative to zone, as used by many DNS APIs,
+// into a fully qualified name. "@" and "" denote the zone apex.
+func Qualify(name, zone string) string {
+	name = strings.TrimSpace(name)
+	zone = NormalizeName(zone)
+	switch {
+	case name == "" || name == "@":
+		return zone
+	case strings.HasSuffix(name, "."):
+		return NormalizeName(name)
+	default:
+		return NormalizeName(name + "." + zone)
+	}
+}
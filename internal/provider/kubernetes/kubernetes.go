@@ -0,0 +1,195 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"sslcheckdomain/internal/kube"
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/pkg/models"
+)
+
+// Resource kinds that can be selected with the resources setting
+const (
+	ResourceIngress     = "ingress"
+	ResourceGateway     = "gateway"
+	ResourceCertificate = "certificate"
+)
+
+// Config holds the settings of the kubernetes config section
+type Config struct {
+	Kubeconfig string   `mapstructure:"kubeconfig"`
+	Context    string   `mapstructure:"context"`
+	Namespace  string   `mapstructure:"namespace"`
+	Manifests  []string `mapstructure:"manifests"`
+	Resources  []string `mapstructure:"resources"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "kubernetes",
+		Description: "Kubernetes Ingress, Gateway API routes and cert-manager Certificates",
+		Settings: []provider.Setting{
+			{Key: "kubeconfig", Env: "KUBECONFIG", Description: "Path to the kubeconfig (default ~/.kube/config or in-cluster); exec credential plugins are supported, auth-provider is not"},
+			{Key: "context", Description: "Kubeconfig context (default current context)"},
+			{Key: "namespace", Description: "Only read objects of this namespace (default all)"},
+			{Key: "manifests", Description: "Read exported manifest files or directories instead of a cluster"},
+			{Key: "resources", Description: "Kinds to read: ingress, gateway, certificate (default all)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// Provider implements the TargetProvider interface for Kubernetes
+type Provider struct {
+	source    kube.Source
+	resources map[string]bool
+}
+
+// New creates a new Kubernetes provider
+func New(cfg Config) (*Provider, error) {
+	source, err := NewSource(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	resources := make(map[string]bool)
+	for _, r := range cfg.Resources {
+		r = strings.ToLower(strings.TrimSpace(r))
+		switch r {
+		case ResourceIngress, ResourceGateway, ResourceCertificate:
+			resources[r] = true
+		default:
+			return nil, fmt.Errorf("unknown resource: %s (valid: ingress, gateway, certificate)", r)
+		}
+	}
+	if len(resources) == 0 {
+		resources = map[string]bool{ResourceIngress: true, ResourceGateway: true, ResourceCertificate: true}
+	}
+
+	return &Provider{
+		source:    source,
+		resources: resources,
+	}, nil
+}
+
+// NewSource returns the object source described by cfg: the manifests
+// when configured, the cluster of the kubeconfig otherwise
+func NewSource(cfg Config) (kube.Source, error) {
+	if len(cfg.Manifests) > 0 {
+		return kube.LoadManifests(cfg.Manifests, cfg.Namespace)
+	}
+	return kube.NewCluster(cfg.Kubeconfig, cfg.Context, cfg.Namespace)
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "kubernetes"
+}
+
+// GetDomains retrieves all hostnames declared in the cluster
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	targets, err := p.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetDomainsByZone retrieves hostnames below zone
+func (p *Provider) GetDomainsByZone(ctx context.Context, zone string) ([]string, error) {
+	targets, err := p.GetTargetsByZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetTargets retrieves all hostnames together with their TLS secrets
+func (p *Provider) GetTargets(ctx context.Context) ([]models.Target, error) {
+	hosts := newHostSet()
+
+	if p.resources[ResourceIngress] {
+		if err := p.addIngresses(ctx, hosts); err != nil {
+			return nil, err
+		}
+	}
+	if p.resources[ResourceGateway] {
+		if err := p.addGateways(ctx, hosts); err != nil {
+			return nil, err
+		}
+	}
+	if p.resources[ResourceCertificate] {
+		if err := p.addCertificates(ctx, hosts); err != nil {
+			return nil, err
+		}
+	}
+
+	return hosts.targets(), nil
+}
+
+// GetTargetsByZone retrieves the targets whose hostname is below zone
+func (p *Provider) GetTargetsByZone(ctx context.Context, zone string) ([]models.Target, error) {
+	targets, err := p.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	zone = provider.NormalizeName(zone)
+	filtered := make([]models.Target, 0, len(targets))
+	for _, target := range targets {
+		if target.Domain == zone || strings.HasSuffix(target.Domain, "."+zone) {
+			filtered = append(filtered, target)
+		}
+	}
+	return filtered, nil
+}
+
+// hostSet collects hostnames in discovery order, keeping the first TLS
+// secret linked to each hostname and port
+type hostSet struct {
+	order []string
+	byKey map[string]*models.Target
+}
+
+func newHostSet() *hostSet {
+	return &hostSet{byKey: make(map[string]*models.Target)}
+}
+
+// add records a hostname served on port (0 = 443) with an optional secret
+func (s *hostSet) add(host string, port int, secret string) {
+	host = provider.NormalizeName(host)
+	if host == "" {
+		return
+	}
+	if port == 443 {
+		port = 0
+	}
+
+	key := fmt.Sprintf("%s:%d", host, port)
+	if existing, ok := s.byKey[key]; ok {
+		if existing.TLSSecret == "" {
+			existing.TLSSecret = secret
+		}
+		return
+	}
+
+	s.order = append(s.order, key)
+	s.byKey[key] = &models.Target{Domain: host, Port: port, TLSSecret: secret}
+}
+
+// targets returns the collected targets
+func (s *hostSet) targets() []models.Target {
+	targets := make([]models.Target, 0, len(s.order))
+	for _, key := range s.order {
+		targets = append(targets, *s.byKey[key])
+	}
+	return targets
+}
@@ -0,0 +1,229 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	"sslcheckdomain/internal/kube"
+)
+
+// ingress is the part of a networking.k8s.io Ingress used here
+type ingress struct {
+	Spec struct {
+		TLS []struct {
+			Hosts      []string `json:"hosts"`
+			SecretName string   `json:"secretName"`
+		} `json:"tls"`
+		Rules []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+	} `json:"spec"`
+}
+
+// gateway is the part of a Gateway API Gateway used here
+type gateway struct {
+	Spec struct {
+		Listeners []listener `json:"listeners"`
+	} `json:"spec"`
+}
+
+// listener is a Gateway listener
+type listener struct {
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+	TLS      *struct {
+		CertificateRefs []struct {
+			Kind      string `json:"kind"`
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"certificateRefs"`
+	} `json:"tls"`
+}
+
+// httpRoute is the part of a Gateway API HTTPRoute used here
+type httpRoute struct {
+	Spec struct {
+		Hostnames  []string `json:"hostnames"`
+		ParentRefs []struct {
+			Kind        string `json:"kind"`
+			Name        string `json:"name"`
+			Namespace   string `json:"namespace"`
+			SectionName string `json:"sectionName"`
+			Port        int    `json:"port"`
+		} `json:"parentRefs"`
+	} `json:"spec"`
+}
+
+// certificate is the part of a cert-manager Certificate used here
+type certificate struct {
+	Spec struct {
+		CommonName string   `json:"commonName"`
+		DNSNames   []string `json:"dnsNames"`
+		SecretName string   `json:"secretName"`
+	} `json:"spec"`
+}
+
+// secretKey returns the namespace/name reference of a secret
+func secretKey(namespace, name string) string {
+	if name == "" {
+		return ""
+	}
+	return namespace + "/" + name
+}
+
+// addIngresses adds the hosts of Ingress rules and TLS sections
+func (p *Provider) addIngresses(ctx context.Context, hosts *hostSet) error {
+	objects, err := p.source.List(ctx, kube.Ingresses)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		var ing ingress
+		if err := object.Decode(&ing); err != nil {
+			return err
+		}
+
+		for _, tls := range ing.Spec.TLS {
+			for _, host := range tls.Hosts {
+				hosts.add(host, 0, secretKey(object.Namespace(), tls.SecretName))
+			}
+		}
+		for _, rule := range ing.Spec.Rules {
+			hosts.add(rule.Host, 0, "")
+		}
+	}
+
+	return nil
+}
+
+// addGateways adds the hostnames of TLS listeners and of the HTTPRoutes
+// attached to them
+func (p *Provider) addGateways(ctx context.Context, hosts *hostSet) error {
+	objects, err := p.source.List(ctx, kube.Gateways)
+	if err != nil {
+		return err
+	}
+
+	gateways := make(map[string]gateway, len(objects))
+	for _, object := range objects {
+		var gw gateway
+		if err := object.Decode(&gw); err != nil {
+			return err
+		}
+		gateways[object.Key()] = gw
+
+		for _, l := range gw.Spec.Listeners {
+			if isTLS(l) && l.Hostname != "" {
+				hosts.add(l.Hostname, l.Port, listenerSecret(object.Namespace(), l))
+			}
+		}
+	}
+
+	routes, err := p.source.List(ctx, kube.HTTPRoutes)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range routes {
+		var route httpRoute
+		if err := object.Decode(&route); err != nil {
+			return err
+		}
+
+		for _, ref := range route.Spec.ParentRefs {
+			if ref.Kind != "" && ref.Kind != "Gateway" {
+				continue
+			}
+			namespace := ref.Namespace
+			if namespace == "" {
+				namespace = object.Namespace()
+			}
+			gw, ok := gateways[namespace+"/"+ref.Name]
+			if !ok {
+				continue
+			}
+
+			for _, host := range route.Spec.Hostnames {
+				for _, l := range gw.Spec.Listeners {
+					if !isTLS(l) || !hostnameMatches(l.Hostname, host) {
+						continue
+					}
+					if ref.SectionName != "" && ref.SectionName != l.Name {
+						continue
+					}
+					if ref.Port != 0 && ref.Port != l.Port {
+						continue
+					}
+					hosts.add(host, l.Port, listenerSecret(namespace, l))
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// addCertificates adds the DNS names of cert-manager Certificates
+func (p *Provider) addCertificates(ctx context.Context, hosts *hostSet) error {
+	objects, err := p.source.List(ctx, kube.Certificates)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objects {
+		var cert certificate
+		if err := object.Decode(&cert); err != nil {
+			return err
+		}
+
+		secret := secretKey(object.Namespace(), cert.Spec.SecretName)
+		if cert.Spec.CommonName != "" {
+			hosts.add(cert.Spec.CommonName, 0, secret)
+		}
+		for _, name := range cert.Spec.DNSNames {
+			hosts.add(name, 0, secret)
+		}
+	}
+
+	return nil
+}
+
+// isTLS reports whether a listener terminates TLS
+func isTLS(l listener) bool {
+	return l.Protocol == "HTTPS" || l.Protocol == "TLS"
+}
+
+// listenerSecret returns the first secret referenced by a listener
+func listenerSecret(namespace string, l listener) string {
+	if l.TLS == nil {
+		return ""
+	}
+	for _, ref := range l.TLS.CertificateRefs {
+		if ref.Kind != "" && ref.Kind != "Secret" {
+			continue
+		}
+		ns := ref.Namespace
+		if ns == "" {
+			ns = namespace
+		}
+		return secretKey(ns, ref.Name)
+	}
+	return ""
+}
+
+// hostnameMatches implements Gateway API listener hostname matching
+func hostnameMatches(listenerHost, host string) bool {
+	listenerHost, host = strings.ToLower(listenerHost), strings.ToLower(host)
+	switch {
+	case listenerHost == "":
+		return true
+	case strings.HasPrefix(listenerHost, "*."):
+		return strings.HasSuffix(host, listenerHost[1:])
+	default:
+		return listenerHost == host
+	}
+}
@@ -0,0 +1,133 @@
+package kubernetes
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"sslcheckdomain/internal/checker"
+	"sslcheckdomain/internal/kube"
+	"sslcheckdomain/pkg/models"
+)
+
+// secretTypeTLS is the type of secrets holding a TLS key pair
+const secretTypeTLS = "kubernetes.io/tls"
+
+// NoteUnreferenced flags expiring secrets that no Ingress uses
+const NoteUnreferenced = "not referenced by any Ingress"
+
+// secret is the part of a core/v1 Secret used here
+type secret struct {
+	Type       string            `json:"type"`
+	Data       map[string]string `json:"data"`
+	StringData map[string]string `json:"stringData"`
+}
+
+// ThresholdFunc returns the warning and critical thresholds of a domain
+type ThresholdFunc func(domain string) (warning, critical int)
+
+// InspectSecrets reports the certificates stored in kubernetes.io/tls
+// secrets, with the thresholds of the certificate's first name. Secrets that
+// need attention and are not referenced by any Ingress are flagged with
+// NoteUnreferenced.
+func InspectSecrets(ctx context.Context, source kube.Source, thresholds ThresholdFunc) ([]models.Certificate, error) {
+	objects, err := source.List(ctx, kube.Secrets)
+	if err != nil {
+		return nil, err
+	}
+
+	referenced, err := IngressSecrets(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+
+	certificates := make([]models.Certificate, 0)
+	for _, object := range objects {
+		var s secret
+		if err := object.Decode(&s); err != nil {
+			return nil, err
+		}
+		if s.Type != secretTypeTLS {
+			continue
+		}
+
+		cert := inspectSecret(object.Key(), s)
+		cert.DetermineStatus(thresholds(cert.Domain))
+		if cert.NeedsAttention() && !referenced[object.Key()] {
+			cert.Notes = append(cert.Notes, NoteUnreferenced)
+		}
+		certificates = append(certificates, cert)
+	}
+
+	return certificates, nil
+}
+
+// inspectSecret parses the tls.crt entry of a secret
+func inspectSecret(key string, s secret) models.Certificate {
+	cert := models.Certificate{
+		Domain:    key,
+		TLSSecret: key,
+	}
+
+	data, err := secretData(s, "tls.crt")
+	if err != nil {
+		cert.Error = err
+		return cert
+	}
+
+	chain, err := checker.ParsePEM(data)
+	if err != nil {
+		cert.Error = err
+		return cert
+	}
+
+	leaf := chain[0]
+	checker.DescribeChain(&cert, chain)
+	switch {
+	case len(leaf.DNSNames) > 0:
+		cert.Domain = leaf.DNSNames[0]
+	case leaf.Subject.CommonName != "":
+		cert.Domain = leaf.Subject.CommonName
+	}
+	return cert
+}
+
+// secretData returns a decoded secret entry, preferring stringData as
+// found in manifests written by hand
+func secretData(s secret, key string) ([]byte, error) {
+	if value, ok := s.StringData[key]; ok {
+		return []byte(value), nil
+	}
+	value, ok := s.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("secret has no %s entry", key)
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s entry: %w", key, err)
+	}
+	return data, nil
+}
+
+// IngressSecrets returns the namespace/name of the TLS secrets referenced
+// by Ingress objects
+func IngressSecrets(ctx context.Context, source kube.Source) (map[string]bool, error) {
+	objects, err := source.List(ctx, kube.Ingresses)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make(map[string]bool)
+	for _, object := range objects {
+		var ing ingress
+		if err := object.Decode(&ing); err != nil {
+			return nil, err
+		}
+		for _, tls := range ing.Spec.TLS {
+			if key := secretKey(object.Namespace(), tls.SecretName); key != "" {
+				secrets[key] = true
+			}
+		}
+	}
+	return secrets, nil
+}
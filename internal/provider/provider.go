@@ -3,6 +3,13 @@ package provider
 import (
 	"context"
 	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/mitchellh/mapstructure"
+	"sslcheckdomain/pkg/models"
 )
 
 // DNSProvider is the interface that all DNS providers must implement
@@ -17,37 +24,243 @@ type DNSProvider interface {
 	Name() string
 }
 
+// TargetProvider is implemented by providers that know more about a host
+// than its name, such as its port or the certificate it should serve.
+// Discovery prefers these methods over the DNSProvider ones.
+type TargetProvider interface {
+	DNSProvider
+
+	// GetTargets retrieves all targets from the provider
+	GetTargets(ctx context.Context) ([]models.Target, error)
+
+	// GetTargetsByZone retrieves targets filtered by zone/parent domain
+	GetTargetsByZone(ctx context.Context, zone string) ([]models.Target, error)
+}
+
+// Domains returns the hostnames of targets
+func Domains(targets []models.Target) []string {
+	names := make([]string, 0, len(targets))
+	for _, target := range targets {
+		names = append(names, target.Domain)
+	}
+	return names
+}
+
+// Settings holds the raw configuration section of a provider
+type Settings map[string]interface{}
+
+// Setting describes a configuration key understood by a provider
+type Setting struct {
+	// Key is the name of the setting within the provider's config section
+	Key string
+	// Env is the environment variable used when the key is not configured
+	Env string
+	// Required marks settings the provider cannot work without
+	Required bool
+	// Description is a short human readable explanation
+	Description string
+}
+
+// Definition describes a provider that can be registered with a factory
+type Definition struct {
+	Name        string
+	Description string
+	Settings    []Setting
+	// New creates the provider from its resolved settings
+	New func(settings Settings) (DNSProvider, error)
+}
+
+// Resolve applies environment defaults to settings and checks required keys
+func (d Definition) Resolve(settings Settings) (Settings, error) {
+	resolved := make(Settings, len(settings))
+	for key, value := range settings {
+		resolved[key] = value
+	}
+
+	for _, s := range d.Settings {
+		if !isEmpty(resolved[s.Key]) {
+			continue
+		}
+		if s.Env != "" {
+			if value := os.Getenv(s.Env); value != "" {
+				resolved[s.Key] = value
+				continue
+			}
+		}
+		if s.Required {
+			if s.Env != "" {
+				return nil, fmt.Errorf("%s is required for %s provider", s.Env, d.Name)
+			}
+			return nil, fmt.Errorf("%s.%s is required for %s provider", d.Name, s.Key, d.Name)
+		}
+	}
+
+	return resolved, nil
+}
+
+// Decode decodes provider settings into a typed config struct.
+// Comma separated strings are accepted for list fields so that
+// settings coming from environment variables work as expected.
+func Decode(settings Settings, out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(map[string]interface{}(settings)); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+	return nil
+}
+
+// isEmpty reports whether a setting value should be treated as unset
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []interface{}:
+		return len(v) == 0
+	case []string:
+		return len(v) == 0
+	default:
+		return false
+	}
+}
+
 // ProviderFactory creates a DNS provider based on the provider type
 type ProviderFactory struct {
-	providers map[string]func() (DNSProvider, error)
+	mu        sync.RWMutex
+	providers map[string]Definition
 }
 
 // NewProviderFactory creates a new provider factory
 func NewProviderFactory() *ProviderFactory {
 	return &ProviderFactory{
-		providers: make(map[string]func() (DNSProvider, error)),
+		providers: make(map[string]Definition),
 	}
 }
 
-// Register registers a provider constructor
-func (f *ProviderFactory) Register(name string, constructor func() (DNSProvider, error)) {
-	f.providers[name] = constructor
+// Register registers a provider definition
+func (f *ProviderFactory) Register(def Definition) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if def.Name == "" || def.New == nil {
+		panic("provider: Register called with incomplete definition")
+	}
+	if _, exists := f.providers[def.Name]; exists {
+		panic("provider: Register called twice for provider " + def.Name)
+	}
+	f.providers[def.Name] = def
+}
+
+// Lookup returns the definition of a registered provider
+func (f *ProviderFactory) Lookup(name string) (Definition, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	def, ok := f.providers[name]
+	return def, ok
+}
+
+// Validate checks that settings satisfy the requirements of a provider
+func (f *ProviderFactory) Validate(name string, settings Settings) error {
+	def, ok := f.Lookup(name)
+	if !ok {
+		return f.unknown(name)
+	}
+	_, err := def.Resolve(settings)
+	return err
 }
 
 // Create creates a provider instance
-func (f *ProviderFactory) Create(name string) (DNSProvider, error) {
-	constructor, ok := f.providers[name]
+func (f *ProviderFactory) Create(name string, settings Settings) (DNSProvider, error) {
+	def, ok := f.Lookup(name)
 	if !ok {
-		return nil, fmt.Errorf("unknown provider: %s", name)
+		return nil, f.unknown(name)
+	}
+
+	resolved, err := def.Resolve(settings)
+	if err != nil {
+		return nil, err
 	}
-	return constructor()
+
+	p, err := def.New(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s provider: %w", name, err)
+	}
+	return p, nil
 }
 
-// AvailableProviders returns list of available providers
+// AvailableProviders returns the sorted list of available providers
 func (f *ProviderFactory) AvailableProviders() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
 	providers := make([]string, 0, len(f.providers))
 	for name := range f.providers {
 		providers = append(providers, name)
 	}
+	sort.Strings(providers)
 	return providers
 }
+
+// Definitions returns all registered definitions sorted by name
+func (f *ProviderFactory) Definitions() []Definition {
+	names := f.AvailableProviders()
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	defs := make([]Definition, 0, len(names))
+	for _, name := range names {
+		defs = append(defs, f.providers[name])
+	}
+	return defs
+}
+
+// unknown returns the error reported for unregistered providers
+func (f *ProviderFactory) unknown(name string) error {
+	return fmt.Errorf("unsupported provider: %s (supported: %s)", name, strings.Join(f.AvailableProviders(), ", "))
+}
+
+// defaultFactory is the registry providers add themselves to from init
+var defaultFactory = NewProviderFactory()
+
+// Register registers a provider with the default factory
+func Register(def Definition) {
+	defaultFactory.Register(def)
+}
+
+// Lookup returns a provider definition from the default factory
+func Lookup(name string) (Definition, bool) {
+	return defaultFactory.Lookup(name)
+}
+
+// Validate checks provider settings against the default factory
+func Validate(name string, settings Settings) error {
+	return defaultFactory.Validate(name, settings)
+}
+
+// Create creates a provider from the default factory
+func Create(name string, settings Settings) (DNSProvider, error) {
+	return defaultFactory.Create(name, settings)
+}
+
+// AvailableProviders returns the providers registered with the default factory
+func AvailableProviders() []string {
+	return defaultFactory.AvailableProviders()
+}
+
+// Definitions returns the definitions registered with the default factory
+func Definitions() []Definition {
+	return defaultFactory.Definitions()
+}
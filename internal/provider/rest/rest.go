@@ -0,0 +1,145 @@
+// Package rest holds the HTTP plumbing shared by the DNS providers that talk
+// to JSON REST APIs: an authenticated client, cached OAuth tokens and a
+// DNSProvider built from a zone and record listing.
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultTimeout bounds each API request
+const DefaultTimeout = 30 * time.Second
+
+// Authorizer adds credentials to an API request
+type Authorizer interface {
+	Authorize(ctx context.Context, req *http.Request) error
+}
+
+// AuthorizerFunc adapts a function to the Authorizer interface
+type AuthorizerFunc func(ctx context.Context, req *http.Request) error
+
+// Authorize calls f
+func (f AuthorizerFunc) Authorize(ctx context.Context, req *http.Request) error {
+	return f(ctx, req)
+}
+
+// Bearer authorizes requests with a static bearer token
+func Bearer(token string) Authorizer {
+	return Header("Authorization", "Bearer "+token)
+}
+
+// Header authorizes requests by setting a header, e.g. an API key
+func Header(name, value string) Authorizer {
+	return AuthorizerFunc(func(_ context.Context, req *http.Request) error {
+		req.Header.Set(name, value)
+		return nil
+	})
+}
+
+// Error is returned for API responses with a non-2xx status
+type Error struct {
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *Error) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("API returned %s", e.Status)
+	}
+	return fmt.Sprintf("API returned %s: %s", e.Status, e.Body)
+}
+
+// Client performs JSON requests against a REST API
+type Client struct {
+	baseURL string
+	auth    Authorizer
+	http    *http.Client
+}
+
+// NewClient creates a client for the API at baseURL. auth may be nil for
+// unauthenticated endpoints such as token exchanges.
+func NewClient(baseURL string, auth Authorizer) *Client {
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		auth:    auth,
+		http:    &http.Client{Timeout: DefaultTimeout},
+	}
+}
+
+// Get requests path, which is relative to the base URL or an absolute URL
+// such as a next page link, and decodes the JSON response into out.
+// The response headers are returned for APIs that page through headers.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) (http.Header, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.resolve(path, query), nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.do(ctx, req, out)
+}
+
+// PostForm posts a URL encoded form and decodes the JSON response into out
+func (c *Client) PostForm(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.resolve(path, nil), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	_, err = c.do(ctx, req, out)
+	return err
+}
+
+// do sends an authorized request and decodes its JSON response
+func (c *Client) do(ctx context.Context, req *http.Request, out interface{}) (http.Header, error) {
+	req.Header.Set("Accept", "application/json")
+	if c.auth != nil {
+		if err := c.auth.Authorize(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &Error{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("invalid API response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
+
+// resolve builds the request URL for path and query
+func (c *Client) resolve(path string, query url.Values) string {
+	target := path
+	if !strings.Contains(path, "://") {
+		target = c.baseURL + path
+	}
+	if len(query) == 0 {
+		return target
+	}
+	if strings.Contains(target, "?") {
+		return target + "&" + query.Encode()
+	}
+	return target + "?" + query.Encode()
+}
@@ -0,0 +1,62 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Token is an OAuth 2.0 access token response
+type Token struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// TokenSource caches access tokens and fetches a new one shortly before
+// the current one expires
+type TokenSource struct {
+	fetch func(ctx context.Context) (Token, error)
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewTokenSource creates a token source that obtains tokens from fetch
+func NewTokenSource(fetch func(ctx context.Context) (Token, error)) *TokenSource {
+	return &TokenSource{fetch: fetch}
+}
+
+// Token returns a valid access token
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != "" && time.Until(ts.expires) > time.Minute {
+		return ts.token, nil
+	}
+
+	token, err := ts.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("token request failed: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token response has no access_token")
+	}
+
+	ts.token = token.AccessToken
+	ts.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return ts.token, nil
+}
+
+// Authorize adds the access token to req as a bearer token
+func (ts *TokenSource) Authorize(ctx context.Context, req *http.Request) error {
+	token, err := ts.Token(ctx)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
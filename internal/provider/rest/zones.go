@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+
+	"sslcheckdomain/internal/provider"
+)
+
+// Zone is a DNS zone as listed by an API
+type Zone struct {
+	// ID identifies the zone in API calls, e.g. a numeric ID or a path
+	ID string
+	// Name is the DNS name of the zone apex
+	Name string
+}
+
+// ZoneAPI is implemented by the API bindings of REST based providers
+type ZoneAPI interface {
+	// Zones lists all zones visible to the credentials
+	Zones(ctx context.Context) ([]Zone, error)
+
+	// Records lists the records of a zone with fully qualified names
+	Records(ctx context.Context, zone Zone) ([]provider.Record, error)
+}
+
+// Provider implements the DNSProvider interface on top of a ZoneAPI
+type Provider struct {
+	name string
+	api  ZoneAPI
+}
+
+// NewProvider creates a DNS provider named name backed by api
+func NewProvider(name string, api ZoneAPI) *Provider {
+	return &Provider{name: name, api: api}
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return p.name
+}
+
+// GetDomains retrieves the hostnames of all zones
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	zones, err := p.api.Zones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+
+	domains := make([]string, 0)
+	for _, zone := range zones {
+		zoneDomains, err := p.zoneDomains(ctx, zone)
+		if err != nil {
+			// Skip zones that cannot be read, like the other providers
+			continue
+		}
+		domains = append(domains, zoneDomains...)
+	}
+	return domains, nil
+}
+
+// GetDomainsByZone retrieves the hostnames of the zone named zoneName
+func (p *Provider) GetDomainsByZone(ctx context.Context, zoneName string) ([]string, error) {
+	zoneName = provider.NormalizeName(zoneName)
+
+	zones, err := p.api.Zones(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list zones: %w", err)
+	}
+	for _, zone := range zones {
+		if provider.NormalizeName(zone.Name) == zoneName {
+			return p.zoneDomains(ctx, zone)
+		}
+	}
+
+	return nil, fmt.Errorf("zone not found: %s", zoneName)
+}
+
+// zoneDomains returns the apex and subdomains of a zone
+func (p *Provider) zoneDomains(ctx context.Context, zone Zone) ([]string, error) {
+	records, err := p.api.Records(ctx, zone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list records of %s: %w", zone.Name, err)
+	}
+
+	apex := provider.NormalizeName(zone.Name)
+	return append([]string{apex}, provider.HostnamesWithWildcards(apex, records)...), nil
+}
@@ -0,0 +1,261 @@
+package terraform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sslcheckdomain/internal/provider"
+)
+
+// Config holds the settings of the terraform config section
+type Config struct {
+	Files []string `mapstructure:"files"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "terraform",
+		Description: "DNS records declared in Terraform state",
+		Settings: []provider.Setting{
+			{Key: "files", Env: "SSL_CHECK_TERRAFORM_STATE", Required: true, Description: "State files or `terraform show -json` output (comma separated, globs allowed)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg.Files)
+		},
+	})
+}
+
+// recordType describes how to read a DNS record resource type
+type recordType struct {
+	// Type is the fixed record type, for resources that declare one type only
+	Type string
+	// ZoneAttr names the attribute holding the zone name, if any
+	ZoneAttr string
+}
+
+// recordTypes maps the supported resource types to their schema. The fqdn
+// and hostname attributes are preferred when the provider computes them.
+var recordTypes = map[string]recordType{
+	"cloudflare_record":        {},
+	"cloudflare_dns_record":    {},
+	"aws_route53_record":       {},
+	"google_dns_record_set":    {},
+	"digitalocean_record":      {ZoneAttr: "domain"},
+	"azurerm_dns_a_record":     {Type: "A", ZoneAttr: "zone_name"},
+	"azurerm_dns_aaaa_record":  {Type: "AAAA", ZoneAttr: "zone_name"},
+	"azurerm_dns_cname_record": {Type: "CNAME", ZoneAttr: "zone_name"},
+}
+
+// Provider implements the DNSProvider interface for Terraform state files
+type Provider struct {
+	files []string
+}
+
+// resource is a resource as stored in state files (version 4)
+type resource struct {
+	Mode      string `json:"mode"`
+	Type      string `json:"type"`
+	Instances []struct {
+		Attributes map[string]interface{} `json:"attributes"`
+	} `json:"instances"`
+}
+
+// module is a module of the `terraform show -json` output
+type module struct {
+	Resources []struct {
+		Mode   string                 `json:"mode"`
+		Type   string                 `json:"type"`
+		Values map[string]interface{} `json:"values"`
+	} `json:"resources"`
+	ChildModules []module `json:"child_modules"`
+}
+
+// document covers both the raw state and the `terraform show -json` format
+type document struct {
+	Version   int        `json:"version"`
+	Resources []resource `json:"resources"`
+	Values    *struct {
+		RootModule module `json:"root_module"`
+	} `json:"values"`
+}
+
+// New creates a new Terraform state provider
+func New(patterns []string) (*Provider, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one state file is required")
+	}
+
+	files := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid state file pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no state files match %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	return &Provider{files: files}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "terraform"
+}
+
+// GetDomains retrieves the hostnames of all A, AAAA and CNAME records
+// declared in the state files
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	seen := make(map[string]bool)
+	domains := make([]string, 0)
+	for _, file := range p.files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		names, err := parseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			if !seen[name] {
+				seen[name] = true
+				domains = append(domains, name)
+			}
+		}
+	}
+
+	return domains, nil
+}
+
+// GetDomainsByZone retrieves the declared hostnames at or below zoneName
+func (p *Provider) GetDomainsByZone(ctx context.Context, zoneName string) ([]string, error) {
+	zoneName = provider.NormalizeName(zoneName)
+
+	all, err := p.GetDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	domains := make([]string, 0)
+	for _, name := range all {
+		if name == zoneName || strings.HasSuffix(name, "."+zoneName) {
+			domains = append(domains, name)
+		}
+	}
+	if len(domains) == 0 {
+		return nil, fmt.Errorf("zone not found: %s", zoneName)
+	}
+
+	return domains, nil
+}
+
+// parseFile extracts the record names of a state file
+func parseFile(file string) ([]string, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var doc document
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %w", file, err)
+	}
+
+	names := make([]string, 0)
+	switch {
+	case doc.Values != nil:
+		names = appendModule(names, doc.Values.RootModule)
+	case doc.Version == 4:
+		for _, r := range doc.Resources {
+			if r.Mode != "managed" {
+				continue
+			}
+			for _, instance := range r.Instances {
+				names = appendRecord(names, r.Type, instance.Attributes)
+			}
+		}
+	case doc.Version != 0:
+		return nil, fmt.Errorf("unsupported state version %d in %s", doc.Version, file)
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// appendModule adds the record names of a module and its child modules
+func appendModule(names []string, m module) []string {
+	for _, r := range m.Resources {
+		if r.Mode == "managed" {
+			names = appendRecord(names, r.Type, r.Values)
+		}
+	}
+	for _, child := range m.ChildModules {
+		names = appendModule(names, child)
+	}
+	return names
+}
+
+// appendRecord adds the hostname of a DNS record resource that should
+// be checked; other resources and record types are ignored
+func appendRecord(names []string, resourceType string, attrs map[string]interface{}) []string {
+	schema, ok := recordTypes[resourceType]
+	if !ok || attrs == nil {
+		return names
+	}
+
+	recType := schema.Type
+	if recType == "" {
+		recType = stringAttr(attrs, "type")
+	}
+	switch strings.ToUpper(recType) {
+	case "A", "AAAA", "CNAME":
+	default:
+		return names
+	}
+
+	name := hostname(schema, attrs)
+	// Names without a dot could not be qualified with their zone
+	if !strings.Contains(name, ".") {
+		return names
+	}
+	return append(names, name)
+}
+
+// hostname returns the fully qualified name of a record resource
+func hostname(schema recordType, attrs map[string]interface{}) string {
+	for _, key := range []string{"fqdn", "hostname"} {
+		if value := stringAttr(attrs, key); value != "" {
+			return provider.NormalizeName(value)
+		}
+	}
+
+	name := stringAttr(attrs, "name")
+	if schema.ZoneAttr == "" {
+		return provider.NormalizeName(name)
+	}
+
+	zone := provider.NormalizeName(stringAttr(attrs, schema.ZoneAttr))
+	normalized := provider.NormalizeName(name)
+	if zone == "" || normalized == zone || strings.HasSuffix(normalized, "."+zone) {
+		return normalized
+	}
+	return provider.Qualify(name, zone)
+}
+
+// stringAttr returns a string attribute, or "" when unset or not a string
+func stringAttr(attrs map[string]interface{}, key string) string {
+	value, _ := attrs[key].(string)
+	return value
+}
@@ -0,0 +1,185 @@
+package webserver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"sslcheckdomain/internal/provider"
+	"sslcheckdomain/internal/webconfig"
+	"sslcheckdomain/pkg/models"
+)
+
+// Config holds the settings of the webserver config section. Each key
+// lists main configuration files of one server type.
+type Config struct {
+	Nginx   []string `mapstructure:"nginx"`
+	Apache  []string `mapstructure:"apache"`
+	HAProxy []string `mapstructure:"haproxy"`
+	Caddy   []string `mapstructure:"caddy"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "webserver",
+		Description: "Sites of local nginx, Apache, HAProxy and Caddy configs",
+		Settings: []provider.Setting{
+			{Key: "nginx", Env: "SSL_CHECK_NGINX_CONFIG", Description: "nginx.conf files (globs allowed)"},
+			{Key: "apache", Env: "SSL_CHECK_APACHE_CONFIG", Description: "Apache httpd.conf/apache2.conf files (globs allowed)"},
+			{Key: "haproxy", Env: "SSL_CHECK_HAPROXY_CONFIG", Description: "haproxy.cfg files (globs allowed)"},
+			{Key: "caddy", Env: "SSL_CHECK_CADDYFILE", Description: "Caddyfiles (globs allowed)"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg)
+		},
+	})
+}
+
+// config is a main configuration file of a server
+type config struct {
+	server string
+	path   string
+}
+
+// Provider implements the TargetProvider interface for web server configs
+type Provider struct {
+	configs []config
+}
+
+// New creates a new web server config provider. Without any configured
+// files the default locations of all servers are used.
+func New(cfg Config) (*Provider, error) {
+	patterns := map[string][]string{
+		webconfig.ServerNginx:   cfg.Nginx,
+		webconfig.ServerApache:  cfg.Apache,
+		webconfig.ServerHAProxy: cfg.HAProxy,
+		webconfig.ServerCaddy:   cfg.Caddy,
+	}
+	servers := []string{webconfig.ServerNginx, webconfig.ServerApache, webconfig.ServerHAProxy, webconfig.ServerCaddy}
+
+	configs := make([]config, 0)
+	for _, server := range servers {
+		for _, pattern := range patterns[server] {
+			matches, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s config pattern %q: %w", server, pattern, err)
+			}
+			if len(matches) == 0 {
+				return nil, fmt.Errorf("no %s config files match %q", server, pattern)
+			}
+			for _, match := range matches {
+				configs = append(configs, config{server: server, path: match})
+			}
+		}
+	}
+
+	if len(configs) == 0 {
+		for _, server := range servers {
+			for _, path := range webconfig.DefaultPaths[server] {
+				if _, err := os.Stat(path); err == nil {
+					configs = append(configs, config{server: server, path: path})
+					break
+				}
+			}
+		}
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("no web server configuration found in the default locations")
+	}
+
+	return &Provider{configs: configs}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "webserver"
+}
+
+// GetTargets returns a target per host name and TLS port of all sites
+func (p *Provider) GetTargets(ctx context.Context) ([]models.Target, error) {
+	targets := make([]models.Target, 0)
+	index := make(map[string]int)
+
+	for _, c := range p.configs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		sites, err := webconfig.Parse(c.server, c.path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, site := range sites {
+			port := site.Port
+			if port == 443 {
+				port = 0
+			}
+			for _, name := range site.Names {
+				key := fmt.Sprintf("%s:%d", name, port)
+				if i, ok := index[key]; ok {
+					// The first site wins like in most servers; flag
+					// conflicting certificate files
+					existing := &targets[i]
+					if site.CertFile != "" && existing.CertFile != "" && site.CertFile != existing.CertFile {
+						existing.Notes = append(existing.Notes, fmt.Sprintf("also configured with %s at %s", site.CertFile, site.Source))
+					}
+					continue
+				}
+				index[key] = len(targets)
+				targets = append(targets, models.Target{
+					Domain:   name,
+					Port:     port,
+					CertFile: site.CertFile,
+				})
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// GetTargetsByZone returns the targets at or below zone
+func (p *Provider) GetTargetsByZone(ctx context.Context, zone string) ([]models.Target, error) {
+	zone = provider.NormalizeName(zone)
+
+	all, err := p.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]models.Target, 0)
+	for _, target := range all {
+		if target.Domain == zone || strings.HasSuffix(target.Domain, "."+zone) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("zone not found: %s", zone)
+	}
+	return targets, nil
+}
+
+// GetDomains returns the host names of all sites
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	targets, err := p.GetTargets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
+
+// GetDomainsByZone returns the host names of the sites at or below zone
+func (p *Provider) GetDomainsByZone(ctx context.Context, zone string) ([]string, error) {
+	targets, err := p.GetTargetsByZone(ctx, zone)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Domains(targets), nil
+}
@@ -0,0 +1,160 @@
+package zonefile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/miekg/dns"
+	"sslcheckdomain/internal/provider"
+)
+
+// Config holds the settings of the zonefile config section
+type Config struct {
+	Files  []string `mapstructure:"files"`
+	Origin string   `mapstructure:"origin"`
+}
+
+func init() {
+	provider.Register(provider.Definition{
+		Name:        "zonefile",
+		Description: "BIND (RFC 1035) master zone files",
+		Settings: []provider.Setting{
+			{Key: "files", Env: "SSL_CHECK_ZONE_FILES", Required: true, Description: "Zone files to read (comma separated, globs allowed)"},
+			{Key: "origin", Description: "Origin for files without $ORIGIN or SOA record"},
+		},
+		New: func(settings provider.Settings) (provider.DNSProvider, error) {
+			var cfg Config
+			if err := provider.Decode(settings, &cfg); err != nil {
+				return nil, err
+			}
+			return New(cfg.Files, cfg.Origin)
+		},
+	})
+}
+
+// Provider implements the DNSProvider interface for BIND zone files
+type Provider struct {
+	files  []string
+	origin string
+}
+
+// zone holds the hostnames extracted from a single zone file
+type zone struct {
+	name    string
+	records []provider.Record
+}
+
+// New creates a new zone file provider
+func New(patterns []string, origin string) (*Provider, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one zone file is required")
+	}
+
+	files := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zone file pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no zone files match %q", pattern)
+		}
+		files = append(files, matches...)
+	}
+
+	if origin != "" {
+		origin = dns.Fqdn(origin)
+	}
+
+	return &Provider{
+		files:  files,
+		origin: origin,
+	}, nil
+}
+
+// Name returns the provider name
+func (p *Provider) Name() string {
+	return "zonefile"
+}
+
+// GetDomains retrieves all domains from the zone files
+func (p *Provider) GetDomains(ctx context.Context) ([]string, error) {
+	domains := make([]string, 0)
+	for _, file := range p.files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		z, err := p.parse(file)
+		if err != nil {
+			return nil, err
+		}
+
+		domains = append(domains, z.name)
+		domains = append(domains, provider.HostnamesWithWildcards(z.name, z.records)...)
+	}
+
+	return domains, nil
+}
+
+// GetDomainsByZone retrieves domains of the zone file whose origin is zoneName
+func (p *Provider) GetDomainsByZone(ctx context.Context, zoneName string) ([]string, error) {
+	zoneName = provider.NormalizeName(zoneName)
+
+	for _, file := range p.files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		z, err := p.parse(file)
+		if err != nil {
+			return nil, err
+		}
+
+		if z.name == zoneName {
+			domains := []string{z.name}
+			return append(domains, provider.HostnamesWithWildcards(z.name, z.records)...), nil
+		}
+	}
+
+	return nil, fmt.Errorf("zone not found: %s", zoneName)
+}
+
+// parse reads a zone file, following $INCLUDE directives. The zone apex is
+// taken from the SOA record, falling back to the configured origin.
+func (p *Provider) parse(file string) (*zone, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zone file: %w", err)
+	}
+	defer f.Close()
+
+	zp := dns.NewZoneParser(f, p.origin, file)
+	zp.SetIncludeAllowed(true)
+
+	z := &zone{}
+	for rr, ok := zp.Next(); ok; rr, ok = zp.Next() {
+		header := rr.Header()
+		if header.Rrtype == dns.TypeSOA && z.name == "" {
+			z.name = provider.NormalizeName(header.Name)
+		}
+		z.records = append(z.records, provider.Record{
+			Name: header.Name,
+			Type: dns.TypeToString[header.Rrtype],
+		})
+	}
+	if err := zp.Err(); err != nil {
+		return nil, fmt.Errorf("failed to parse zone file: %w", err)
+	}
+
+	if z.name == "" {
+		if p.origin == "" {
+			return nil, fmt.Errorf("zone file %s has no SOA record and no origin is configured", file)
+		}
+		z.name = provider.NormalizeName(p.origin)
+	}
+
+	return z, nil
+}
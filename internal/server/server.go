@@ -0,0 +1,345 @@
+// Package server runs sslcheckdomain as a long-running exporter: certificates
+// are re-checked in the background on a schedule and the latest report is
+// served over HTTP as metrics and JSON, next to on-demand probes.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"sslcheckdomain/internal/metrics"
+	"sslcheckdomain/pkg/models"
+)
+
+// DefaultListen is the default address of the HTTP server
+const DefaultListen = ":9219"
+
+// ErrProbeDenied is returned by a ProbeFunc for targets it may not check
+var ErrProbeDenied = errors.New("target not allowed")
+
+// RefreshFunc discovers and checks all certificates. It may return a report
+// together with an error when some sources failed.
+type RefreshFunc func(ctx context.Context) (*models.CertificateReport, error)
+
+// ProbeFunc checks a single target on demand. It returns an error wrapping
+// ErrProbeDenied for targets that may not be probed.
+type ProbeFunc func(ctx context.Context, target string, sni string) (models.Certificate, error)
+
+// Options configures a Server
+type Options struct {
+	// Listen is the address of the HTTP server, defaults to DefaultListen
+	Listen string
+	// Interval is the time between the start of two refreshes
+	Interval time.Duration
+	// Refresh produces the report served by /metrics and /api/report
+	Refresh RefreshFunc
+	// Probe serves /probe, which is disabled when nil
+	Probe ProbeFunc
+	// Logger receives refresh and server messages, defaults to stderr
+	Logger *log.Logger
+}
+
+// Server serves the latest report and refreshes it in the background
+type Server struct {
+	opts Options
+
+	mu          sync.RWMutex
+	report      *models.CertificateReport
+	lastRefresh time.Time
+	lastError   error
+	duration    time.Duration
+}
+
+// New creates a server
+func New(opts Options) *Server {
+	if opts.Listen == "" {
+		opts.Listen = DefaultListen
+	}
+	if opts.Logger == nil {
+		opts.Logger = log.Default()
+	}
+	return &Server{opts: opts}
+}
+
+// Run refreshes the report every interval and serves HTTP until ctx is
+// cancelled. The first refresh starts immediately; requests are answered
+// from the previous report while a refresh runs.
+func (s *Server) Run(ctx context.Context) error {
+	if s.opts.Interval <= 0 {
+		return fmt.Errorf("refresh interval must be greater than 0")
+	}
+
+	srv := &http.Server{
+		Addr:              s.opts.Listen,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.refreshLoop(ctx)
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		s.opts.Logger.Printf("listening on %s", s.opts.Listen)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err = srv.Shutdown(shutdownCtx)
+	case err = <-errCh:
+	}
+
+	wg.Wait()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// refreshLoop refreshes the report until ctx is cancelled
+func (s *Server) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.Refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh runs a refresh and stores its report. A report returned with an
+// error replaces the previous one; a failed refresh keeps it.
+func (s *Server) Refresh(ctx context.Context) {
+	start := time.Now()
+	report, err := s.opts.Refresh(ctx)
+	duration := time.Since(start)
+
+	switch {
+	case err != nil && report == nil:
+		s.opts.Logger.Printf("refresh failed after %s: %v", duration.Round(time.Millisecond), err)
+	case err != nil:
+		s.opts.Logger.Printf("refresh partially failed after %s: %v", duration.Round(time.Millisecond), err)
+	default:
+		s.opts.Logger.Printf("checked %d certificates in %s", report.TotalDomains, duration.Round(time.Millisecond))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if report != nil {
+		s.report = report
+	}
+	s.lastRefresh = time.Now()
+	s.lastError = err
+	s.duration = duration
+}
+
+// Handler returns the HTTP handler of the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", s.handleIndex)
+	mux.HandleFunc("/metrics", s.handleMetrics)
+	mux.HandleFunc("/healthz", s.handleHealth)
+	mux.HandleFunc("/api/report", s.handleReport)
+	if s.opts.Probe != nil {
+		mux.HandleFunc("/probe", s.handleProbe)
+	}
+	return mux
+}
+
+// Report returns the latest report, nil before the first refresh completed
+func (s *Server) Report() *models.CertificateReport {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report
+}
+
+// snapshot returns the latest report and refresh state
+func (s *Server) snapshot() (*models.CertificateReport, time.Time, time.Duration, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report, s.lastRefresh, s.duration, s.lastError
+}
+
+// handleIndex lists the endpoints
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<html><head><title>sslcheckdomain</title></head><body>
+<h1>sslcheckdomain</h1>
+<ul>
+<li><a href="/metrics">/metrics</a></li>
+<li><a href="/api/report">/api/report</a></li>
+<li><a href="/healthz">/healthz</a></li>
+<li>/probe?target=example.com:443</li>
+</ul>
+</body></html>
+`)
+}
+
+// handleMetrics serves the metrics of the latest report and of the refresh
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	report, lastRefresh, duration, err := s.snapshot()
+	if report == nil {
+		http.Error(w, "no report yet, first refresh in progress", http.StatusServiceUnavailable)
+		return
+	}
+
+	families := metrics.Families(report)
+	families = append(families,
+		metrics.Family{
+			Name:    "ssl_exporter_refresh_success",
+			Help:    "Whether the last refresh discovered and checked all certificates",
+			Type:    metrics.TypeGauge,
+			Metrics: []metrics.Metric{{Value: boolValue(err == nil)}},
+		},
+		metrics.Family{
+			Name:    "ssl_exporter_refresh_duration_seconds",
+			Help:    "Duration of the last refresh",
+			Type:    metrics.TypeGauge,
+			Unit:    "seconds",
+			Metrics: []metrics.Metric{{Value: duration.Seconds()}},
+		},
+		metrics.Family{
+			Name:    "ssl_exporter_last_refresh_seconds",
+			Help:    "Time of the last refresh as unix timestamp",
+			Type:    metrics.TypeGauge,
+			Unit:    "seconds",
+			Metrics: []metrics.Metric{{Value: float64(lastRefresh.Unix())}},
+		},
+	)
+	writeMetrics(w, r, families)
+}
+
+// handleHealth reports whether a recent refresh produced a report. The
+// report is stale when the last three refreshes failed.
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report, lastRefresh, _, err := s.snapshot()
+
+	health := struct {
+		Status      string    `json:"status"`
+		LastRefresh time.Time `json:"last_refresh,omitempty"`
+		Error       string    `json:"error,omitempty"`
+	}{Status: "ok", LastRefresh: lastRefresh}
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	code := http.StatusOK
+	switch {
+	case report == nil:
+		health.Status = "starting"
+		code = http.StatusServiceUnavailable
+	case time.Since(report.Timestamp) > 3*s.opts.Interval:
+		health.Status = "stale"
+		code = http.StatusServiceUnavailable
+	}
+
+	writeJSON(w, code, health)
+}
+
+// handleReport serves the latest report as JSON
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	report, _, _, _ := s.snapshot()
+	if report == nil {
+		http.Error(w, "no report yet, first refresh in progress", http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+// handleProbe checks the target parameter on demand and serves its metrics,
+// like the blackbox exporter
+func (s *Server) handleProbe(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "target parameter is missing", http.StatusBadRequest)
+		return
+	}
+
+	start := time.Now()
+	cert, err := s.opts.Probe(r.Context(), target, r.URL.Query().Get("sni"))
+	if errors.Is(err, ErrProbeDenied) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	duration := time.Since(start)
+
+	report := &models.CertificateReport{
+		Timestamp:    start,
+		TotalDomains: 1,
+		Certificates: []models.Certificate{cert},
+	}
+	report.Summary.Add(cert.Status)
+
+	families := []metrics.Family{
+		{
+			Name:    "probe_success",
+			Help:    "Whether the certificate could be retrieved",
+			Type:    metrics.TypeGauge,
+			Metrics: []metrics.Metric{{Value: boolValue(cert.Error == nil && cert.Status != models.StatusError)}},
+		},
+		{
+			Name:    "probe_duration_seconds",
+			Help:    "Duration of the probe",
+			Type:    metrics.TypeGauge,
+			Unit:    "seconds",
+			Metrics: []metrics.Metric{{Value: duration.Seconds()}},
+		},
+	}
+	families = append(families, metrics.Families(report)...)
+	writeMetrics(w, r, families)
+}
+
+// writeMetrics writes the families in the format the scraper accepts
+func writeMetrics(w http.ResponseWriter, r *http.Request, families []metrics.Family) {
+	write, contentType := metrics.WriteText, metrics.ContentTypeText
+	if strings.Contains(r.Header.Get("Accept"), "application/openmetrics-text") {
+		write, contentType = metrics.WriteOpenMetrics, metrics.ContentTypeOpenMetrics
+	}
+	w.Header().Set("Content-Type", contentType)
+	_ = write(w, families)
+}
+
+// writeJSON writes v as indented JSON
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(v)
+}
+
+// boolValue converts a bool to 1 or 0
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,265 @@
+package webconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseApache reads an Apache httpd configuration and returns the virtual
+// hosts with SSLEngine on. Relative paths are resolved against ServerRoot,
+// or the directory of the main configuration file when it is not set.
+func ParseApache(path string) ([]Site, error) {
+	p := &apacheParser{root: filepath.Dir(path), seen: make(map[string]bool)}
+	directives, err := p.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultCert := ""
+	for _, d := range flatten(directives) {
+		if d.name == "sslcertificatefile" && len(d.args) > 0 && defaultCert == "" {
+			defaultCert = d.args[0]
+		}
+	}
+
+	sites := make([]Site, 0)
+	for _, d := range flatten(directives) {
+		if d.name == "<virtualhost" {
+			if site, ok := p.virtualHost(d, defaultCert); ok {
+				sites = append(sites, site)
+			}
+		}
+	}
+	return sites, nil
+}
+
+// apacheParser holds the state of parsing a configuration with includes
+type apacheParser struct {
+	root string
+	seen map[string]bool
+}
+
+// parseFile parses a configuration file into sections and directives.
+// Directive and section names are lower-cased as Apache ignores their case.
+func (p *apacheParser) parseFile(path string) ([]directive, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if p.seen[abs] {
+		return nil, fmt.Errorf("include loop at %s", path)
+	}
+	p.seen[abs] = true
+	defer delete(p.seen, abs)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apache config: %w", err)
+	}
+	defer f.Close()
+
+	// stack holds the open sections; stack[0] is the file itself
+	stack := []*directive{{}}
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	var pending string
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasSuffix(line, "\\") {
+			pending += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		line = strings.TrimSpace(pending + line)
+		pending = ""
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		words := splitApacheWords(line)
+		name := strings.ToLower(words[0])
+		d := directive{name: name, args: words[1:], file: path, line: lineNumber}
+		top := stack[len(stack)-1]
+
+		switch {
+		case strings.HasPrefix(name, "</"):
+			if len(stack) == 1 || "<"+strings.TrimSuffix(name[2:], ">") != top.name {
+				return nil, fmt.Errorf("%s:%d: unexpected %s", path, lineNumber, words[0])
+			}
+			stack = stack[:len(stack)-1]
+			parent := stack[len(stack)-1]
+			parent.block = append(parent.block, *top)
+
+		case strings.HasPrefix(name, "<"):
+			d.name = strings.TrimSuffix(name, ">")
+			if len(d.args) > 0 {
+				d.args[len(d.args)-1] = strings.TrimSuffix(d.args[len(d.args)-1], ">")
+			}
+			stack = append(stack, &d)
+
+		case name == "include" || name == "includeoptional":
+			if len(d.args) == 0 {
+				continue
+			}
+			included, err := p.include(d.args[0])
+			if err != nil {
+				if name == "includeoptional" {
+					continue
+				}
+				return nil, fmt.Errorf("%s: %w", d.source(), err)
+			}
+			top.block = append(top.block, included...)
+
+		case name == "serverroot":
+			if len(d.args) > 0 {
+				p.root = d.args[0]
+			}
+
+		default:
+			top.block = append(top.block, d)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read apache config: %w", err)
+	}
+	if len(stack) > 1 {
+		open := stack[len(stack)-1]
+		return nil, fmt.Errorf("%s: unclosed %s>", open.source(), open.name)
+	}
+
+	return stack[0].block, nil
+}
+
+// include parses the files matched by an Include directive
+func (p *apacheParser) include(pattern string) ([]directive, error) {
+	files, err := includeFiles(pattern, p.root)
+	if err != nil {
+		return nil, err
+	}
+
+	directives := make([]directive, 0)
+	for _, file := range files {
+		included, err := p.parseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, included...)
+	}
+	return directives, nil
+}
+
+// virtualHost converts a VirtualHost section with SSLEngine on to a site
+func (p *apacheParser) virtualHost(vhost directive, certFile string) (Site, bool) {
+	port := 0
+	for _, address := range vhost.args {
+		if _, p, ok := splitPort(address); ok {
+			port = p
+			break
+		}
+	}
+
+	var (
+		names   []string
+		sslOn   bool
+		ownCert string
+	)
+	for _, d := range flatten(vhost.block) {
+		switch d.name {
+		case "servername":
+			if len(d.args) > 0 {
+				names = appendName(names, apacheServerName(d.args[0]))
+			}
+		case "serveralias":
+			for _, alias := range d.args {
+				names = appendName(names, alias)
+			}
+		case "sslengine":
+			sslOn = len(d.args) > 0 && strings.EqualFold(d.args[0], "on")
+		case "sslcertificatefile":
+			if len(d.args) > 0 && ownCert == "" {
+				ownCert = d.args[0]
+			}
+		}
+	}
+
+	if !sslOn || len(names) == 0 {
+		return Site{}, false
+	}
+	if port == 0 {
+		port = 443
+	}
+	if ownCert != "" {
+		certFile = ownCert
+	}
+
+	return Site{
+		Names:    names,
+		Port:     port,
+		CertFile: resolvePath(certFile, p.root),
+		Source:   vhost.source(),
+	}, true
+}
+
+// flatten returns the directives of a block including those of nested
+// conditional sections such as <IfModule> and <IfDefine>
+func flatten(directives []directive) []directive {
+	flat := make([]directive, 0, len(directives))
+	for _, d := range directives {
+		if strings.HasPrefix(d.name, "<if") {
+			flat = append(flat, flatten(d.block)...)
+			continue
+		}
+		flat = append(flat, d)
+	}
+	return flat
+}
+
+// apacheServerName strips the scheme and port ServerName may carry
+func apacheServerName(name string) string {
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	if host, _, ok := splitPort(name); ok {
+		return host
+	}
+	return name
+}
+
+// splitApacheWords splits a configuration line into words, honoring
+// double and single quotes
+func splitApacheWords(line string) []string {
+	words := make([]string, 0)
+	var (
+		word   strings.Builder
+		quote  byte
+		inWord bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote != 0:
+			word.WriteByte(c)
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words
+}
@@ -0,0 +1,302 @@
+package webconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseCaddy reads a Caddyfile and returns its HTTPS site blocks. Sites
+// are linked to a certificate file only when they use "tls <cert> <key>";
+// certificates Caddy manages itself are checked by name only. File imports
+// and snippets are followed.
+func ParseCaddy(path string) ([]Site, error) {
+	p := &caddyParser{
+		dir:      filepath.Dir(path),
+		seen:     make(map[string]bool),
+		snippets: make(map[string][]directive),
+	}
+	directives, err := p.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]Site, 0)
+	for i, d := range directives {
+		body := d.block
+		if d.block == nil {
+			// A Caddyfile with a single site may omit the braces, in which
+			// case the first line holds the addresses and the rest of the
+			// file is the site body
+			if i > 0 {
+				continue
+			}
+			body = directives[1:]
+		}
+		sites = append(sites, p.siteBlock(d, body)...)
+		if d.block == nil {
+			break
+		}
+	}
+	return sites, nil
+}
+
+// caddyParser holds the state of parsing a Caddyfile with imports
+type caddyParser struct {
+	dir      string
+	seen     map[string]bool
+	snippets map[string][]directive
+}
+
+// parseFile parses a Caddyfile and returns its top-level directives; the
+// global options block and snippet definitions are removed
+func (p *caddyParser) parseFile(path string) ([]directive, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if p.seen[abs] {
+		return nil, fmt.Errorf("import loop at %s", path)
+	}
+	p.seen[abs] = true
+	defer delete(p.seen, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Caddyfile: %w", err)
+	}
+
+	tokens, err := caddyTokens(string(data), path)
+	if err != nil {
+		return nil, err
+	}
+	parsed, rest, err := parseCaddyBlock(tokens, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%s:%d: unexpected \"}\"", path, rest[0].line)
+	}
+
+	directives := make([]directive, 0, len(parsed))
+	for _, d := range parsed {
+		switch {
+		case d.name == "{":
+			// Global options
+		case strings.HasPrefix(d.name, "(") && strings.HasSuffix(d.name, ")"):
+			p.snippets[strings.Trim(d.name, "()")] = d.block
+		case d.name == "import" && len(d.args) > 0:
+			if _, ok := p.snippets[d.args[0]]; ok {
+				continue
+			}
+			files, err := includeFiles(d.args[0], p.dir)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", d.source(), err)
+			}
+			for _, file := range files {
+				imported, err := p.parseFile(file)
+				if err != nil {
+					return nil, err
+				}
+				directives = append(directives, imported...)
+			}
+		default:
+			directives = append(directives, d)
+		}
+	}
+	return directives, nil
+}
+
+// siteBlock returns one site per HTTPS port of a site block
+func (p *caddyParser) siteBlock(addresses directive, body []directive) []Site {
+	certFile := ""
+	for _, d := range p.expandSnippets(body) {
+		// "tls internal" and "tls <email>" let Caddy manage the certificate
+		if d.name == "tls" && len(d.args) >= 2 && certFile == "" {
+			certFile = resolvePath(d.args[0], p.dir)
+		}
+	}
+
+	byPort := make(map[int][]string)
+	ports := make([]int, 0)
+	for _, word := range append([]string{addresses.name}, addresses.args...) {
+		for _, address := range strings.Split(word, ",") {
+			name, port, ok := caddyAddress(address)
+			if !ok {
+				continue
+			}
+			if _, exists := byPort[port]; !exists {
+				ports = append(ports, port)
+			}
+			byPort[port] = append(byPort[port], name)
+		}
+	}
+
+	sites := make([]Site, 0, len(ports))
+	for _, port := range ports {
+		sites = append(sites, Site{
+			Names:    byPort[port],
+			Port:     port,
+			CertFile: certFile,
+			Source:   addresses.source(),
+		})
+	}
+	return sites
+}
+
+// expandSnippets replaces snippet imports with the snippet directives
+func (p *caddyParser) expandSnippets(directives []directive) []directive {
+	expanded := make([]directive, 0, len(directives))
+	for _, d := range directives {
+		if d.name == "import" && len(d.args) > 0 {
+			if snippet, ok := p.snippets[d.args[0]]; ok {
+				expanded = append(expanded, snippet...)
+				continue
+			}
+		}
+		expanded = append(expanded, d)
+	}
+	return expanded
+}
+
+// caddyAddress parses a site address such as "example.com",
+// "https://example.com:8443" or "*.example.com". ok is false for
+// addresses that are not served over HTTPS or have no host name.
+func caddyAddress(address string) (string, int, bool) {
+	address = strings.TrimSpace(address)
+	port := 443
+	if i := strings.Index(address, "://"); i >= 0 {
+		scheme := strings.ToLower(address[:i])
+		if scheme != "https" {
+			return "", 0, false
+		}
+		address = address[i+3:]
+	}
+	if i := strings.Index(address, "/"); i >= 0 {
+		address = address[:i]
+	}
+	if host, p, ok := splitPort(address); ok {
+		address, port = host, p
+	}
+	if port == 80 {
+		return "", 0, false
+	}
+
+	name := hostName(address)
+	return name, port, name != ""
+}
+
+// parseCaddyBlock parses the lines of a block into directives until the
+// closing brace or the end of input and returns the tokens after the brace.
+// A directive is the words of one line, optionally followed by a block.
+func parseCaddyBlock(tokens []token, file string) ([]directive, []token, error) {
+	directives := make([]directive, 0)
+	for len(tokens) > 0 {
+		tok := tokens[0]
+		if isBrace(tok, "}") {
+			return directives, tokens, nil
+		}
+
+		d := directive{name: tok.text, file: file, line: tok.line}
+		tokens = tokens[1:]
+
+		// A line holding only "{" opens the global options block
+		opensBlock := isBrace(tok, "{")
+		if !opensBlock {
+			line := tok.line
+			continued := strings.HasSuffix(tok.text, ",")
+			for len(tokens) > 0 && (tokens[0].line == line || continued) && !isBrace(tokens[0], "{") {
+				d.args = append(d.args, tokens[0].text)
+				line = tokens[0].line
+				continued = strings.HasSuffix(tokens[0].text, ",")
+				tokens = tokens[1:]
+			}
+			// A block opens with "{" at the end of the directive line
+			if len(tokens) > 0 && isBrace(tokens[0], "{") && tokens[0].line == line {
+				opensBlock = true
+				tokens = tokens[1:]
+			}
+		}
+
+		if opensBlock {
+			block, rest, err := parseCaddyBlock(tokens, file)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(rest) == 0 {
+				return nil, nil, fmt.Errorf("%s:%d: missing \"}\"", file, d.line)
+			}
+			d.block = block
+			tokens = rest[1:]
+		}
+
+		directives = append(directives, d)
+	}
+	return directives, nil, nil
+}
+
+// isBrace reports whether tok is the unquoted brace b
+func isBrace(tok token, b string) bool {
+	return tok.text == b && !tok.quoted
+}
+
+// caddyTokens splits a Caddyfile into words with their line numbers.
+// Quoted strings and backtick strings form single words and "#" starts a
+// comment at the beginning of a word.
+func caddyTokens(text, file string) ([]token, error) {
+	tokens := make([]token, 0)
+	line := 1
+	var (
+		word      strings.Builder
+		wordStart int
+	)
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, token{text: word.String(), line: wordStart})
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\n':
+			flush()
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		case c == '#' && word.Len() == 0:
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+			i--
+		case (c == '"' || c == '`') && word.Len() == 0:
+			start := line
+			var quoted strings.Builder
+			i++
+			for ; i < len(text) && text[i] != c; i++ {
+				if c == '"' && text[i] == '\\' && i+1 < len(text) {
+					i++
+				}
+				if text[i] == '\n' {
+					line++
+				}
+				quoted.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("%s:%d: unterminated string", file, start)
+			}
+			tokens = append(tokens, token{text: quoted.String(), line: start, quoted: true})
+		default:
+			if word.Len() == 0 {
+				wordStart = line
+			}
+			word.WriteByte(c)
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
@@ -0,0 +1,215 @@
+package webconfig
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// haproxySections are the keywords that start a configuration section
+var haproxySections = map[string]bool{
+	"global": true, "defaults": true, "frontend": true, "backend": true,
+	"listen": true, "resolvers": true, "peers": true, "userlist": true,
+	"mailers": true, "program": true, "http-errors": true, "ring": true,
+	"cache": true, "crt-store": true,
+}
+
+// ParseHAProxy reads an HAProxy configuration and returns one site per
+// certificate of each "bind ... ssl crt" line. HAProxy selects certificates
+// by SNI, so the names of a site are those of its certificate, or the SNI
+// filters of a crt-list entry. Relative paths are resolved against
+// crt-base, or the directory of the configuration file.
+func ParseHAProxy(path string) ([]Site, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read haproxy config: %w", err)
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(path)
+	crtBase := dir
+	section := ""
+	sites := make([]Site, 0)
+
+	scanner := bufio.NewScanner(f)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+
+		keyword := words[0]
+		if haproxySections[keyword] {
+			section = keyword
+			continue
+		}
+
+		switch {
+		case section == "global" && keyword == "crt-base" && len(words) > 1:
+			crtBase = resolvePath(words[1], dir)
+		case (section == "frontend" || section == "listen") && keyword == "bind":
+			source := fmt.Sprintf("%s:%d", path, lineNumber)
+			bindSites, err := haproxyBind(words[1:], crtBase, source)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", source, err)
+			}
+			sites = append(sites, bindSites...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read haproxy config: %w", err)
+	}
+
+	return sites, nil
+}
+
+// haproxyBind returns the sites of a bind line's certificates
+func haproxyBind(args []string, crtBase, source string) ([]Site, error) {
+	if len(args) == 0 || !hasArg(args[1:], "ssl") {
+		return nil, nil
+	}
+
+	// A bind line may listen on several comma separated addresses
+	ports := make([]int, 0)
+	for _, address := range strings.Split(args[0], ",") {
+		if i := strings.Index(address, "@"); i >= 0 {
+			address = address[i+1:]
+		}
+		if _, port, ok := splitHAProxyPort(address); ok {
+			ports = append(ports, port)
+		}
+	}
+
+	entries := make([]crtEntry, 0)
+	for i := 1; i+1 < len(args); i++ {
+		switch args[i] {
+		case "crt":
+			found, err := crtFiles(resolvePath(args[i+1], crtBase))
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, found...)
+		case "crt-list":
+			found, err := crtList(resolvePath(args[i+1], crtBase), crtBase)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, found...)
+		}
+	}
+
+	sites := make([]Site, 0)
+	for _, entry := range entries {
+		names := entry.names
+		if len(names) == 0 {
+			found, err := certificateNames(entry.file)
+			if err != nil {
+				return nil, err
+			}
+			names = found
+		}
+		if len(names) == 0 {
+			continue
+		}
+		for _, port := range ports {
+			sites = append(sites, Site{Names: names, Port: port, CertFile: entry.file, Source: source})
+		}
+	}
+	return sites, nil
+}
+
+// crtEntry is a certificate file with optional SNI filters
+type crtEntry struct {
+	file  string
+	names []string
+}
+
+// crtFiles returns the certificate at path, or all certificates in it when
+// path is a directory
+func crtFiles(path string) ([]crtEntry, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	if !info.IsDir() {
+		return []crtEntry{{file: path}}, nil
+	}
+
+	files, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read certificate directory: %w", err)
+	}
+	entries := make([]crtEntry, 0, len(files))
+	for _, file := range files {
+		// Key, OCSP and issuer files are loaded alongside certificates
+		switch filepath.Ext(file.Name()) {
+		case ".key", ".ocsp", ".issuer", ".sctl":
+			continue
+		}
+		if !file.IsDir() {
+			entries = append(entries, crtEntry{file: filepath.Join(path, file.Name())})
+		}
+	}
+	return entries, nil
+}
+
+// crtList reads a crt-list file: one certificate per line, followed by
+// optional [ssl options] and SNI filters
+func crtList(path, crtBase string) ([]crtEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crt-list: %w", err)
+	}
+
+	entries := make([]crtEntry, 0)
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Drop the bracketed SSL options
+		if open := strings.Index(line, "["); open >= 0 {
+			if end := strings.Index(line[open:], "]"); end >= 0 {
+				line = line[:open] + line[open+end+1:]
+			}
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("%s:%d: missing certificate", path, i+1)
+		}
+		entry := crtEntry{file: resolvePath(fields[0], crtBase)}
+		for _, filter := range fields[1:] {
+			// Negative filters exclude names
+			if !strings.HasPrefix(filter, "!") {
+				entry.names = appendName(entry.names, filter)
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// splitHAProxyPort extracts the port of an HAProxy address, which may be an
+// unbracketed IPv6 address such as ":::443" or a port range like "443-444"
+func splitHAProxyPort(address string) (string, int, bool) {
+	i := strings.LastIndex(address, ":")
+	if i < 0 {
+		return address, 0, false
+	}
+	host, port := address[:i], address[i+1:]
+	if j := strings.Index(port, "-"); j >= 0 {
+		port = port[:j]
+	}
+	_, p, ok := splitPort(port)
+	return host, p, ok
+}
@@ -0,0 +1,330 @@
+package webconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseNginx reads an nginx configuration and returns the server blocks
+// with an ssl listener. Relative include and certificate paths are resolved
+// against the directory of the main configuration file.
+func ParseNginx(path string) ([]Site, error) {
+	p := &nginxParser{dir: filepath.Dir(path), seen: make(map[string]bool)}
+	directives, err := p.parseFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sites := make([]Site, 0)
+	for _, d := range directives {
+		if d.name == "http" {
+			sites = append(sites, p.httpSites(d.block)...)
+		}
+	}
+	return sites, nil
+}
+
+// nginxParser holds the state of parsing a configuration with includes
+type nginxParser struct {
+	dir  string
+	seen map[string]bool
+}
+
+// parseFile tokenizes and parses a configuration file
+func (p *nginxParser) parseFile(path string) ([]directive, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if p.seen[abs] {
+		return nil, fmt.Errorf("include loop at %s", path)
+	}
+	p.seen[abs] = true
+	defer delete(p.seen, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nginx config: %w", err)
+	}
+
+	tokens, err := nginxTokens(string(data), path)
+	if err != nil {
+		return nil, err
+	}
+
+	directives, rest, err := p.parseBlock(tokens, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("%s:%d: unexpected \"}\"", path, rest[0].line)
+	}
+	return directives, nil
+}
+
+// parseBlock parses directives until the closing brace of the block or the
+// end of input and returns the remaining tokens after the brace
+func (p *nginxParser) parseBlock(tokens []token, file string) ([]directive, []token, error) {
+	directives := make([]directive, 0)
+	var current *directive
+
+	for len(tokens) > 0 {
+		tok := tokens[0]
+		tokens = tokens[1:]
+
+		switch {
+		case tok.text == "}" && !tok.quoted:
+			if current != nil {
+				return nil, nil, fmt.Errorf("%s:%d: missing \";\"", file, current.line)
+			}
+			return directives, append([]token{tok}, tokens...), nil
+
+		case tok.text == ";" && !tok.quoted:
+			if current == nil {
+				continue
+			}
+			if current.name == "include" && len(current.args) == 1 {
+				included, err := p.include(current.args[0])
+				if err != nil {
+					return nil, nil, fmt.Errorf("%s: %w", current.source(), err)
+				}
+				directives = append(directives, included...)
+			} else {
+				directives = append(directives, *current)
+			}
+			current = nil
+
+		case tok.text == "{" && !tok.quoted:
+			if current == nil {
+				return nil, nil, fmt.Errorf("%s:%d: unexpected \"{\"", file, tok.line)
+			}
+			block, rest, err := p.parseBlock(tokens, file)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(rest) == 0 {
+				return nil, nil, fmt.Errorf("%s:%d: missing \"}\"", file, current.line)
+			}
+			current.block = block
+			directives = append(directives, *current)
+			current = nil
+			tokens = rest[1:]
+
+		default:
+			if current == nil {
+				current = &directive{name: tok.text, file: file, line: tok.line}
+			} else {
+				current.args = append(current.args, tok.text)
+			}
+		}
+	}
+
+	if current != nil {
+		return nil, nil, fmt.Errorf("%s:%d: missing \";\"", file, current.line)
+	}
+	return directives, nil, nil
+}
+
+// include parses the files matched by an include directive
+func (p *nginxParser) include(pattern string) ([]directive, error) {
+	files, err := includeFiles(pattern, p.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	directives := make([]directive, 0)
+	for _, file := range files {
+		included, err := p.parseFile(file)
+		if err != nil {
+			return nil, err
+		}
+		directives = append(directives, included...)
+	}
+	return directives, nil
+}
+
+// httpSites returns the TLS sites of an http block. The ssl_certificate of
+// the http block applies to servers that do not set their own.
+func (p *nginxParser) httpSites(http []directive) []Site {
+	defaultCert := ""
+	for _, d := range http {
+		if d.name == "ssl_certificate" && len(d.args) > 0 && defaultCert == "" {
+			defaultCert = d.args[0]
+		}
+	}
+
+	sites := make([]Site, 0)
+	for _, d := range http {
+		if d.name == "server" {
+			sites = append(sites, p.serverSites(d, defaultCert)...)
+		}
+	}
+	return sites
+}
+
+// serverSites returns one site per ssl port of a server block
+func (p *nginxParser) serverSites(server directive, certFile string) []Site {
+	var (
+		names     []string
+		ports     []int
+		plainPort []int
+		sslOn     bool
+		ownCert   string
+	)
+
+	for _, d := range server.block {
+		switch d.name {
+		case "server_name":
+			for _, arg := range d.args {
+				// ".example.com" matches the domain and all its subdomains
+				if strings.HasPrefix(arg, ".") {
+					names = appendName(names, arg[1:])
+					arg = "*" + arg
+				}
+				names = appendName(names, arg)
+			}
+		case "listen":
+			if len(d.args) == 0 || strings.HasPrefix(d.args[0], "unix:") {
+				continue
+			}
+			_, port, ok := splitPort(d.args[0])
+			if !ok {
+				port = 80
+			}
+			if hasArg(d.args[1:], "ssl") {
+				ports = append(ports, port)
+			} else {
+				plainPort = append(plainPort, port)
+			}
+		case "ssl":
+			sslOn = len(d.args) > 0 && d.args[0] == "on"
+		case "ssl_certificate":
+			if len(d.args) > 0 && ownCert == "" {
+				ownCert = d.args[0]
+			}
+		}
+	}
+
+	// The deprecated "ssl on" enables TLS on all listeners
+	if sslOn {
+		ports = append(ports, plainPort...)
+		if len(ports) == 0 {
+			ports = []int{443}
+		}
+	}
+	if len(ports) == 0 || len(names) == 0 {
+		return nil
+	}
+
+	if ownCert != "" {
+		certFile = ownCert
+	}
+	// Certificates selected at runtime cannot be compared
+	if strings.Contains(certFile, "$") {
+		certFile = ""
+	}
+
+	sites := make([]Site, 0, len(ports))
+	for _, port := range ports {
+		sites = append(sites, Site{
+			Names:    names,
+			Port:     port,
+			CertFile: resolvePath(certFile, p.dir),
+			Source:   server.source(),
+		})
+	}
+	return sites
+}
+
+// appendName adds a cleaned host name unless it cannot be checked
+func appendName(names []string, name string) []string {
+	if name = hostName(name); name != "" {
+		return append(names, name)
+	}
+	return names
+}
+
+// hasArg reports whether args contains arg
+func hasArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// token is a word of a configuration file
+type token struct {
+	text   string
+	line   int
+	quoted bool
+}
+
+// nginxTokens splits nginx configuration text into words, quoted strings
+// and the special characters ";", "{" and "}"
+func nginxTokens(text, file string) ([]token, error) {
+	tokens := make([]token, 0)
+	line := 1
+	var (
+		word      strings.Builder
+		wordStart int
+	)
+
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, token{text: word.String(), line: wordStart})
+			word.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\n':
+			flush()
+			line++
+		case c == ' ' || c == '\t' || c == '\r':
+			flush()
+		case c == '#' && word.Len() == 0:
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+			i--
+		case c == ';' || c == '{' || c == '}':
+			flush()
+			tokens = append(tokens, token{text: string(c), line: line})
+		case (c == '"' || c == '\'') && word.Len() == 0:
+			start := line
+			var quoted strings.Builder
+			i++
+			for ; i < len(text) && text[i] != c; i++ {
+				if text[i] == '\\' && i+1 < len(text) {
+					i++
+				}
+				if text[i] == '\n' {
+					line++
+				}
+				quoted.WriteByte(text[i])
+			}
+			if i >= len(text) {
+				return nil, fmt.Errorf("%s:%d: unterminated string", file, start)
+			}
+			tokens = append(tokens, token{text: quoted.String(), line: start, quoted: true})
+		default:
+			if word.Len() == 0 {
+				wordStart = line
+			}
+			if c == '\\' && i+1 < len(text) {
+				i++
+				c = text[i]
+			}
+			word.WriteByte(c)
+		}
+	}
+	flush()
+
+	return tokens, nil
+}
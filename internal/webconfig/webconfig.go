@@ -0,0 +1,188 @@
+// Package webconfig extracts the TLS sites of web server configurations:
+// the host names a server answers for, the port and the certificate file
+// it serves. nginx, Apache httpd, HAProxy and Caddy (Caddyfile) are
+// supported. Include directives are followed.
+package webconfig
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"sslcheckdomain/internal/checker"
+)
+
+// Supported servers
+const (
+	ServerNginx   = "nginx"
+	ServerApache  = "apache"
+	ServerHAProxy = "haproxy"
+	ServerCaddy   = "caddy"
+)
+
+// DefaultPaths lists the usual main configuration files of each server
+var DefaultPaths = map[string][]string{
+	ServerNginx:   {"/etc/nginx/nginx.conf", "/usr/local/etc/nginx/nginx.conf"},
+	ServerApache:  {"/etc/apache2/apache2.conf", "/etc/httpd/conf/httpd.conf", "/usr/local/etc/apache24/httpd.conf"},
+	ServerHAProxy: {"/etc/haproxy/haproxy.cfg", "/usr/local/etc/haproxy/haproxy.cfg"},
+	ServerCaddy:   {"/etc/caddy/Caddyfile", "/usr/local/etc/caddy/Caddyfile"},
+}
+
+// Site is a TLS virtual host found in a configuration
+type Site struct {
+	// Names are the host names of the site; wildcards are kept as is
+	Names []string
+	// Port is the TLS port the site listens on
+	Port int
+	// CertFile is the certificate file served, empty when the server
+	// obtains certificates itself or the path cannot be determined
+	CertFile string
+	// Source is the file and line the site is defined at
+	Source string
+}
+
+// Parse reads the main configuration file of server and returns its sites
+func Parse(server, path string) ([]Site, error) {
+	switch server {
+	case ServerNginx:
+		return ParseNginx(path)
+	case ServerApache:
+		return ParseApache(path)
+	case ServerHAProxy:
+		return ParseHAProxy(path)
+	case ServerCaddy:
+		return ParseCaddy(path)
+	default:
+		return nil, fmt.Errorf("unsupported web server: %s", server)
+	}
+}
+
+// directive is a configuration statement with its arguments and the
+// directives of its block, if any
+type directive struct {
+	name  string
+	args  []string
+	block []directive
+	file  string
+	line  int
+}
+
+// source returns the location of the directive
+func (d directive) source() string {
+	return fmt.Sprintf("%s:%d", d.file, d.line)
+}
+
+// includeFiles resolves an include pattern relative to dir. Patterns that
+// match nothing yield no files, as most servers accept them.
+func includeFiles(pattern, dir string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(dir, pattern)
+	}
+
+	if !strings.ContainsAny(pattern, "*?[") {
+		info, err := os.Stat(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", pattern, err)
+		}
+		// Apache includes all files of a directory
+		if info.IsDir() {
+			pattern = filepath.Join(pattern, "*")
+		} else {
+			return []string{pattern}, nil
+		}
+	}
+
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include pattern %q: %w", pattern, err)
+	}
+
+	files := make([]string, 0, len(matches))
+	for _, match := range matches {
+		if info, err := os.Stat(match); err == nil && !info.IsDir() {
+			files = append(files, match)
+		}
+	}
+	return files, nil
+}
+
+// resolvePath makes a file path found in a configuration absolute
+func resolvePath(path, dir string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
+
+// hostName cleans a configured server name. It returns "" for names that
+// cannot be checked, such as catch-all names, regular expressions, names
+// with variables and names without a dot like localhost.
+func hostName(name string) string {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	switch {
+	case name == "", name == "_", name == "localhost":
+		return ""
+	case strings.HasPrefix(name, "~"), strings.ContainsAny(name, "${}()^\\"):
+		return ""
+	case strings.HasSuffix(name, ".*"):
+		// nginx trailing wildcards cannot be expanded
+		return ""
+	case net.ParseIP(strings.Trim(name, "[]")) != nil:
+		return ""
+	case !strings.Contains(name, "."):
+		return ""
+	}
+	return name
+}
+
+// splitPort splits a listen address such as "*:443", "[::]:8443",
+// "10.0.0.1" or "443" into its host and port. ok is false when the address
+// has no port.
+func splitPort(address string) (host string, port int, ok bool) {
+	if p, err := strconv.Atoi(address); err == nil {
+		return "", p, true
+	}
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return address, 0, false
+	}
+	p, err := strconv.Atoi(portText)
+	if err != nil {
+		return host, 0, false
+	}
+	return host, p, true
+}
+
+// certificateNames returns the DNS names of the leaf certificate of a PEM
+// file, falling back to the subject common name
+func certificateNames(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	chain, err := checker.ParsePEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return leafNames(chain[0]), nil
+}
+
+// leafNames returns the host names a certificate is valid for
+func leafNames(cert *x509.Certificate) []string {
+	names := cert.DNSNames
+	if len(names) == 0 && cert.Subject.CommonName != "" {
+		names = []string{cert.Subject.CommonName}
+	}
+
+	cleaned := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = hostName(name); name != "" {
+			cleaned = append(cleaned, name)
+		}
+	}
+	return cleaned
+}
@@ -8,39 +8,80 @@ import (
 type CertificateStatus string
 
 const (
-	StatusExpired CertificateStatus = "expired"
-	StatusWarning CertificateStatus = "warning"
-	StatusOK      CertificateStatus = "ok"
-	StatusError   CertificateStatus = "error"
+	StatusExpired  CertificateStatus = "expired"
+	StatusCritical CertificateStatus = "critical"
+	StatusWarning  CertificateStatus = "warning"
+	StatusOK       CertificateStatus = "ok"
+	StatusError    CertificateStatus = "error"
 )
 
 // Certificate represents SSL certificate information
 type Certificate struct {
-	Domain      string            `json:"domain"`
-	Status      CertificateStatus `json:"status"`
-	ExpiresAt   time.Time         `json:"expires_at"`
-	IssuedAt    time.Time         `json:"issued_at"`
-	Issuer      string            `json:"issuer"`
-	Subject     string            `json:"subject"`
-	DaysLeft    int               `json:"days_left"`
-	SerialNumber string           `json:"serial_number"`
-	Error       error             `json:"error,omitempty"`
+	Domain       string            `json:"domain"`
+	Status       CertificateStatus `json:"status"`
+	ExpiresAt    time.Time         `json:"expires_at"`
+	IssuedAt     time.Time         `json:"issued_at"`
+	Issuer       string            `json:"issuer"`
+	Subject      string            `json:"subject"`
+	DaysLeft     int               `json:"days_left"`
+	WarningDays  int               `json:"warning_days"`
+	CriticalDays int               `json:"critical_days"`
+	SerialNumber string            `json:"serial_number"`
+	SANs         []string          `json:"sans,omitempty"`
+	Chain        []ChainCert       `json:"chain,omitempty"`
+	Port         int               `json:"port,omitempty"`
+	SNI          string            `json:"sni,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	TLSSecret    string            `json:"tls_secret,omitempty"`
+	CertFile     string            `json:"cert_file,omitempty"`
+	Wildcard     string            `json:"wildcard,omitempty"`
+	Notes        []string          `json:"notes,omitempty"`
+	Provider     string            `json:"provider,omitempty"`
+	Instance     string            `json:"instance,omitempty"`
+	Duration     time.Duration     `json:"duration_ns,omitempty"`
+	Error        error             `json:"error,omitempty"`
+}
+
+// ChainCert describes an intermediate or root certificate presented
+// along with the leaf
+type ChainCert struct {
+	Subject      string    `json:"subject"`
+	Issuer       string    `json:"issuer"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	SerialNumber string    `json:"serial_number"`
 }
 
 // CertificateReport represents a collection of certificate checks
 type CertificateReport struct {
-	Timestamp    time.Time      `json:"timestamp"`
-	TotalDomains int            `json:"total_domains"`
-	Summary      ReportSummary  `json:"summary"`
-	Certificates []Certificate  `json:"certificates"`
+	Timestamp    time.Time     `json:"timestamp"`
+	TotalDomains int           `json:"total_domains"`
+	Summary      ReportSummary `json:"summary"`
+	Certificates []Certificate `json:"certificates"`
 }
 
 // ReportSummary provides aggregated statistics
 type ReportSummary struct {
-	Expired int `json:"expired"`
-	Warning int `json:"warning"`
-	OK      int `json:"ok"`
-	Error   int `json:"error"`
+	Expired  int `json:"expired"`
+	Critical int `json:"critical"`
+	Warning  int `json:"warning"`
+	OK       int `json:"ok"`
+	Error    int `json:"error"`
+}
+
+// Add counts a certificate with the given status
+func (s *ReportSummary) Add(status CertificateStatus) {
+	switch status {
+	case StatusExpired:
+		s.Expired++
+	case StatusCritical:
+		s.Critical++
+	case StatusWarning:
+		s.Warning++
+	case StatusOK:
+		s.OK++
+	case StatusError:
+		s.Error++
+	}
 }
 
 // DaysUntilExpiration calculates days left until expiration
@@ -48,8 +89,11 @@ func (c *Certificate) DaysUntilExpiration() int {
 	return int(time.Until(c.ExpiresAt).Hours() / 24)
 }
 
-// DetermineStatus determines the status based on days left and threshold
-func (c *Certificate) DetermineStatus(warningThreshold int) {
+// DetermineStatus determines the status based on days left and the
+// warning and critical thresholds
+func (c *Certificate) DetermineStatus(warningThreshold, criticalThreshold int) {
+	c.WarningDays = warningThreshold
+	c.CriticalDays = criticalThreshold
 	if c.Error != nil {
 		c.Status = StatusError
 		return
@@ -60,6 +104,8 @@ func (c *Certificate) DetermineStatus(warningThreshold int) {
 	switch {
 	case c.DaysLeft < 0:
 		c.Status = StatusExpired
+	case c.DaysLeft <= criticalThreshold:
+		c.Status = StatusCritical
 	case c.DaysLeft <= warningThreshold:
 		c.Status = StatusWarning
 	default:
@@ -74,5 +120,5 @@ func (c *Certificate) IsHealthy() bool {
 
 // NeedsAttention returns true if certificate needs attention
 func (c *Certificate) NeedsAttention() bool {
-	return c.Status == StatusExpired || c.Status == StatusWarning
+	return c.Status == StatusExpired || c.Status == StatusCritical || c.Status == StatusWarning
 }
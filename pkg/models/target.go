@@ -0,0 +1,36 @@
+package models
+
+// Target represents a host whose certificate should be checked
+type Target struct {
+	Domain string `json:"domain"`
+	// Port defaults to 443 when zero
+	Port int `json:"port,omitempty"`
+	// SNI is the server name sent in the handshake, defaults to Domain
+	SNI  string   `json:"sni,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+	// Threshold overrides the warning threshold in days when non-zero
+	Threshold int `json:"threshold,omitempty"`
+	// Critical overrides the critical threshold in days when non-zero
+	Critical int `json:"critical,omitempty"`
+	// TLSSecret is the namespace/name of the Kubernetes secret the
+	// endpoint is expected to serve
+	TLSSecret string `json:"tls_secret,omitempty"`
+	// CertFile is the certificate file the web server is configured to
+	// serve for this host, compared with the served certificate
+	CertFile string `json:"cert_file,omitempty"`
+	// Wildcard is the wildcard record this host was expanded from
+	Wildcard string `json:"wildcard,omitempty"`
+	// Notes are findings made during discovery, reported with the result
+	Notes    []string `json:"notes,omitempty"`
+	Provider string   `json:"provider,omitempty"`
+	Instance string   `json:"instance,omitempty"`
+}
+
+// TargetsFromDomains converts plain domain names to targets
+func TargetsFromDomains(domains []string) []Target {
+	targets := make([]Target, 0, len(domains))
+	for _, domain := range domains {
+		targets = append(targets, Target{Domain: domain})
+	}
+	return targets
+}